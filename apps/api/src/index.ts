@@ -18,11 +18,26 @@ import {
   confirmAdditionalGenerationsPaymentHandler,
   purchaseAdditionalGenerationsHandler,
 } from "./typed-handlers/ai-usage-handlers";
+import {
+  approveCliAuthorizeHandler,
+  approveCliDeviceAuthHandler,
+  authorizeCliHandler,
+  exchangeCliAuthCodeHandler,
+  pollCliDeviceAuthHandler,
+  startCliDeviceAuthHandler,
+  verifyCliDeviceAuthHandler,
+} from "./typed-handlers/cli-auth-handlers";
 import {
   addCliStatusUpdateItemHandler,
+  createCliTokenHandler,
   editCliStatusUpdateHandler,
   getCliStatusUpdateByDateHandler,
+  getCliWhoamiHandler,
+  listCliStatusUpdatesInRangeHandler,
+  listCliTokensHandler,
   listRecentStatusUpdatesHandler,
+  patchCliStatusUpdateHandler,
+  revokeCliTokenHandler,
   showCurrentStatusUpdateHandler,
   undoLastCliStatusUpdateItemHandler,
 } from "./typed-handlers/cli-handlers";
@@ -425,10 +440,23 @@ const typedHandlersApp = typedHandlersHonoServer(
     generateStatusUpdateHandler,
     addCliStatusUpdateItemHandler,
     editCliStatusUpdateHandler,
+    patchCliStatusUpdateHandler,
     getCliStatusUpdateByDateHandler,
     undoLastCliStatusUpdateItemHandler,
     showCurrentStatusUpdateHandler,
     listRecentStatusUpdatesHandler,
+    listCliStatusUpdatesInRangeHandler,
+    authorizeCliHandler,
+    approveCliAuthorizeHandler,
+    exchangeCliAuthCodeHandler,
+    startCliDeviceAuthHandler,
+    verifyCliDeviceAuthHandler,
+    approveCliDeviceAuthHandler,
+    pollCliDeviceAuthHandler,
+    createCliTokenHandler,
+    listCliTokensHandler,
+    revokeCliTokenHandler,
+    getCliWhoamiHandler,
     getGithubIntegrationHandler,
     githubIntegrationCallbackHandler,
     listGithubRepositoriesHandler,
@@ -476,6 +504,7 @@ const typedHandlersApp = typedHandlersHonoServer(
     undoLastCliStatusUpdateItemHandler,
     showCurrentStatusUpdateHandler,
     listRecentStatusUpdatesHandler,
+    listCliStatusUpdatesInRangeHandler,
     runScheduleHandler,
     updateUserOnboardingHandler,
     createOnboardingRecommendedAutomationsHandler,
@@ -4,10 +4,11 @@ import ResetPassword from "@asyncstatus/email/auth/reset-password-email";
 import VerificationEmail from "@asyncstatus/email/auth/verification-email";
 import { betterAuth } from "better-auth";
 import { drizzleAdapter } from "better-auth/adapters/drizzle";
-import { customSession, jwt } from "better-auth/plugins";
+import { customSession, jwt, twoFactor } from "better-auth/plugins";
 import { desc, eq } from "drizzle-orm";
 import type { Resend } from "resend";
 import { authCookiesPlugin } from "./auth-cookies-plugin";
+import { cliSessionPlugin } from "./cli-session-plugin";
 import type { Bindings } from "./env";
 
 export function createAuth(env: Bindings, db: Db, resend: Resend) {
@@ -203,6 +204,9 @@ export function createAuth(env: Bindings, db: Db, resend: Resend) {
       useSecureCookies: env.NODE_ENV === "production",
     },
     plugins: [
+      // Sign in with a password answers twoFactorRedirect once a user enabled
+      // TOTP, and the session is only created by /two-factor/verify-totp
+      twoFactor({ issuer: "AsyncStatus" }),
       customSession(async (session) => {
         let activeOrganizationSlug =
           (session.user as any).activeOrganizationSlug ||
@@ -220,6 +224,7 @@ export function createAuth(env: Bindings, db: Db, resend: Resend) {
         return { ...session, session: { ...session.session, activeOrganizationSlug } };
       }),
       authCookiesPlugin(),
+      cliSessionPlugin(),
       jwt({
         jwt: {
           definePayload(session) {
@@ -0,0 +1,35 @@
+import { APIError, createAuthEndpoint } from "better-auth/api";
+import { setSessionCookie } from "better-auth/cookies";
+import type { BetterAuthPlugin } from "better-auth/types";
+import { z } from "zod/v4";
+
+// Signs a user in for the CLI once a login was approved in the browser, so
+// Better Auth creates and signs the session cookie the same way as on sign in.
+// The endpoint is server only, the CLI auth handlers call it through auth.api.
+export const cliSessionPlugin = () => {
+  return {
+    id: "cli-session",
+    endpoints: {
+      createCliSession: createAuthEndpoint(
+        "/cli-session",
+        {
+          method: "POST",
+          body: z.object({ userId: z.string() }),
+          metadata: { SERVER_ONLY: true },
+        },
+        async (ctx) => {
+          const user = await ctx.context.internalAdapter.findUserById(ctx.body.userId);
+          if (!user) {
+            throw new APIError("NOT_FOUND", { message: "User not found" });
+          }
+          const session = await ctx.context.internalAdapter.createSession(user.id, ctx);
+          if (!session) {
+            throw new APIError("INTERNAL_SERVER_ERROR", { message: "Failed to create session" });
+          }
+          await setSessionCookie(ctx, { session, user });
+          return ctx.json({ ok: true });
+        },
+      ),
+    },
+  } satisfies BetterAuthPlugin;
+};
@@ -0,0 +1,68 @@
+import { typedContract } from "@asyncstatus/typed-handlers";
+import { z } from "zod/v4";
+
+const cliAuthorizeRequest = z.strictObject({
+  redirectUri: z.url(),
+  state: z.string().min(16),
+  codeChallenge: z.string().min(43).max(128),
+  codeChallengeMethod: z.literal("S256"),
+  clientName: z.string().max(200).optional(),
+});
+
+export const authorizeCliContract = typedContract(
+  "get /cli/auth/authorize",
+  cliAuthorizeRequest,
+  z.instanceof(Response),
+);
+
+export const approveCliAuthorizeContract = typedContract(
+  "post /cli/auth/authorize",
+  cliAuthorizeRequest.extend({ decision: z.enum(["approve", "deny"]) }),
+  z.instanceof(Response),
+);
+
+export const exchangeCliAuthCodeContract = typedContract(
+  "post /cli/auth/token",
+  z.strictObject({
+    code: z.string().min(1),
+    codeVerifier: z.string().min(43).max(128),
+    redirectUri: z.url(),
+  }),
+  z.strictObject({
+    sessionCookie: z.string(),
+  }),
+);
+
+export const startCliDeviceAuthContract = typedContract(
+  "post /cli/auth/device",
+  z.strictObject({}),
+  z.strictObject({
+    deviceCode: z.string(),
+    userCode: z.string(),
+    verificationUri: z.string(),
+    verificationUriComplete: z.string(),
+    expiresIn: z.number(),
+    interval: z.number(),
+  }),
+);
+
+export const verifyCliDeviceAuthContract = typedContract(
+  "get /cli/auth/device/verify",
+  z.strictObject({ userCode: z.string().optional() }),
+  z.instanceof(Response),
+);
+
+export const approveCliDeviceAuthContract = typedContract(
+  "post /cli/auth/device/approve",
+  z.strictObject({ userCode: z.string().min(1) }),
+  z.instanceof(Response),
+);
+
+export const pollCliDeviceAuthContract = typedContract(
+  "post /cli/auth/device/token",
+  z.strictObject({ deviceCode: z.string().min(1) }),
+  z.strictObject({
+    status: z.enum(["pending", "approved"]),
+    sessionCookie: z.string().optional(),
+  }),
+);
@@ -0,0 +1,298 @@
+import { TypedHandlersError, typedHandler } from "@asyncstatus/typed-handlers";
+import { parseSetCookieHeader } from "better-auth/cookies";
+import type { Auth } from "../lib/auth";
+import type { TypedHandlersContext } from "../lib/env";
+import {
+  approveCliAuthorizeContract,
+  approveCliDeviceAuthContract,
+  authorizeCliContract,
+  exchangeCliAuthCodeContract,
+  pollCliDeviceAuthContract,
+  startCliDeviceAuthContract,
+  verifyCliDeviceAuthContract,
+} from "./cli-auth-contracts";
+
+// Authorization codes are exchanged right after the browser redirect
+const CLI_AUTH_CODE_TTL_SECONDS = 5 * 60;
+// Device codes leave time to open the link on another device and sign in
+const CLI_DEVICE_CODE_TTL_SECONDS = 10 * 60;
+const CLI_DEVICE_POLL_INTERVAL_SECONDS = 5;
+// Unambiguous characters for codes typed in by hand
+const USER_CODE_ALPHABET = "BCDFGHJKLMNPQRSTVWXZ";
+
+interface CliAuthCode {
+  userId: string;
+  codeChallenge: string;
+  redirectUri: string;
+}
+
+interface CliDeviceAuth {
+  userCode: string;
+  userId: string | null;
+}
+
+function isLoopbackRedirectUri(redirectUri: string) {
+  const url = new URL(redirectUri);
+  return (
+    url.protocol === "http:" &&
+    (url.hostname === "127.0.0.1" || url.hostname === "[::1]" || url.hostname === "localhost") &&
+    url.port !== ""
+  );
+}
+
+function base64Url(bytes: Uint8Array) {
+  return btoa(String.fromCharCode(...bytes))
+    .replace(/\+/g, "-")
+    .replace(/\//g, "_")
+    .replace(/=+$/, "");
+}
+
+function randomCode() {
+  return base64Url(crypto.getRandomValues(new Uint8Array(32)));
+}
+
+function randomUserCode() {
+  const bytes = crypto.getRandomValues(new Uint8Array(8));
+  const chars = Array.from(bytes, (byte) => USER_CODE_ALPHABET[byte % USER_CODE_ALPHABET.length]);
+  return `${chars.slice(0, 4).join("")}-${chars.slice(4).join("")}`;
+}
+
+async function s256(codeVerifier: string) {
+  const digest = await crypto.subtle.digest("SHA-256", new TextEncoder().encode(codeVerifier));
+  return base64Url(new Uint8Array(digest));
+}
+
+function escapeHtml(value: string) {
+  return value
+    .replace(/&/g, "&amp;")
+    .replace(/</g, "&lt;")
+    .replace(/>/g, "&gt;")
+    .replace(/"/g, "&quot;");
+}
+
+function htmlPage(title: string, body: string, status = 200) {
+  return new Response(
+    `<!doctype html><html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>${escapeHtml(title)}</title></head><body style="font-family: system-ui, sans-serif; max-width: 28rem; margin: 4rem auto; padding: 0 1rem;"><h1 style="font-size: 1.25rem;">${escapeHtml(title)}</h1>${body}</body></html>`,
+    { status, headers: { "Content-Type": "text/html; charset=utf-8" } },
+  );
+}
+
+function signInPage(webAppUrl: string) {
+  return htmlPage(
+    "Sign in to AsyncStatus",
+    `<p>Sign in to AsyncStatus in this browser to connect the CLI, then reload this page.</p><p><a href="${escapeHtml(`${webAppUrl}/login`)}" target="_blank" rel="noopener">Sign in</a></p>`,
+    401,
+  );
+}
+
+// Creates a new session for the CLI and returns its session cookie as Better
+// Auth sets it on sign in, so the CLI can get its JWT from /auth/token
+async function createCliSessionCookie(auth: Auth, req: Request, userId: string) {
+  const { headers } = await auth.api.createCliSession({
+    body: { userId },
+    headers: req.headers,
+    returnHeaders: true,
+  });
+  const name = (await auth.$context).authCookies.sessionToken.name;
+  const cookie = parseSetCookieHeader(headers.get("set-cookie") ?? "").get(name);
+  if (!cookie) {
+    throw new TypedHandlersError({
+      code: "INTERNAL_SERVER_ERROR",
+      message: "Failed to create session",
+    });
+  }
+
+  return `${name}=${cookie.value}`;
+}
+
+function assertLoopbackRedirectUri(redirectUri: string) {
+  if (!isLoopbackRedirectUri(redirectUri)) {
+    throw new TypedHandlersError({
+      code: "BAD_REQUEST",
+      message: "The redirect URI must be a loopback address",
+    });
+  }
+}
+
+function hiddenInputs(values: Record<string, string | undefined>) {
+  return Object.entries(values)
+    .filter(([, value]) => value !== undefined)
+    .map(([name, value]) => `<input type="hidden" name="${name}" value="${escapeHtml(value!)}">`)
+    .join("");
+}
+
+export const authorizeCliHandler = typedHandler<
+  TypedHandlersContext,
+  typeof authorizeCliContract
+>(authorizeCliContract, async ({ input, session, webAppUrl }) => {
+  assertLoopbackRedirectUri(input.redirectUri);
+
+  if (!session) {
+    return signInPage(webAppUrl);
+  }
+
+  // Any local process can open this page, so the code is only issued once the
+  // user approves, the same way the device flow needs a click
+  const clientName = input.clientName?.trim() || "AsyncStatus CLI";
+  const port = new URL(input.redirectUri).port;
+  return htmlPage(
+    "Connect the AsyncStatus CLI",
+    `<p><strong>${escapeHtml(clientName)}</strong> is asking to sign in as ${escapeHtml(session.user.email)}. It will receive the session on this computer at port ${escapeHtml(port)}.</p><p>Only continue if you just ran <code>asyncstatus login</code>.</p><form method="post" action="/cli/auth/authorize" enctype="multipart/form-data">${hiddenInputs(input)}<button type="submit" name="decision" value="approve">Connect</button> <button type="submit" name="decision" value="deny">Deny</button></form>`,
+  );
+});
+
+export const approveCliAuthorizeHandler = typedHandler<
+  TypedHandlersContext,
+  typeof approveCliAuthorizeContract
+>(approveCliAuthorizeContract, async ({ input, session, authKv, webAppUrl, redirect }) => {
+  assertLoopbackRedirectUri(input.redirectUri);
+
+  if (!session) {
+    return signInPage(webAppUrl);
+  }
+
+  const callbackUrl = new URL(input.redirectUri);
+  callbackUrl.searchParams.set("state", input.state);
+  if (input.decision === "deny") {
+    callbackUrl.searchParams.set("error", "access_denied");
+    return redirect(callbackUrl.toString());
+  }
+
+  const code = randomCode();
+  await authKv.put(
+    `cli-auth-code:${code}`,
+    JSON.stringify({
+      userId: session.user.id,
+      codeChallenge: input.codeChallenge,
+      redirectUri: input.redirectUri,
+    } satisfies CliAuthCode),
+    { expirationTtl: CLI_AUTH_CODE_TTL_SECONDS },
+  );
+
+  callbackUrl.searchParams.set("code", code);
+  return redirect(callbackUrl.toString());
+});
+
+export const exchangeCliAuthCodeHandler = typedHandler<
+  TypedHandlersContext,
+  typeof exchangeCliAuthCodeContract
+>(exchangeCliAuthCodeContract, async ({ input, authKv, auth, req }) => {
+  const key = `cli-auth-code:${input.code}`;
+  const authCode = await authKv.get<CliAuthCode>(key, { type: "json" });
+  if (!authCode) {
+    throw new TypedHandlersError({
+      code: "UNAUTHORIZED",
+      message: "Invalid or expired authorization code",
+    });
+  }
+  // Codes are single use, even when the exchange fails
+  await authKv.delete(key);
+
+  if (
+    authCode.redirectUri !== input.redirectUri ||
+    authCode.codeChallenge !== (await s256(input.codeVerifier))
+  ) {
+    throw new TypedHandlersError({
+      code: "UNAUTHORIZED",
+      message: "Invalid authorization code",
+    });
+  }
+
+  return { sessionCookie: await createCliSessionCookie(auth, req, authCode.userId) };
+});
+
+export const startCliDeviceAuthHandler = typedHandler<
+  TypedHandlersContext,
+  typeof startCliDeviceAuthContract
+>(startCliDeviceAuthContract, async ({ authKv, betterAuthUrl }) => {
+  const deviceCode = randomCode();
+  const userCode = randomUserCode();
+
+  await authKv.put(
+    `cli-device:${deviceCode}`,
+    JSON.stringify({ userCode, userId: null } satisfies CliDeviceAuth),
+    { expirationTtl: CLI_DEVICE_CODE_TTL_SECONDS },
+  );
+  await authKv.put(`cli-device-user-code:${userCode}`, deviceCode, {
+    expirationTtl: CLI_DEVICE_CODE_TTL_SECONDS,
+  });
+
+  const verificationUri = `${betterAuthUrl}/cli/auth/device/verify`;
+  return {
+    deviceCode,
+    userCode,
+    verificationUri,
+    verificationUriComplete: `${verificationUri}?userCode=${userCode}`,
+    expiresIn: CLI_DEVICE_CODE_TTL_SECONDS,
+    interval: CLI_DEVICE_POLL_INTERVAL_SECONDS,
+  };
+});
+
+export const verifyCliDeviceAuthHandler = typedHandler<
+  TypedHandlersContext,
+  typeof verifyCliDeviceAuthContract
+>(verifyCliDeviceAuthContract, async ({ input, session, webAppUrl }) => {
+  if (!session) {
+    return signInPage(webAppUrl);
+  }
+
+  // Approving needs a click, so a link alone can't sign the CLI of someone else in
+  return htmlPage(
+    "Connect the AsyncStatus CLI",
+    `<p>Signed in as ${escapeHtml(session.user.email)}. Check that the code matches the one shown in your terminal.</p><form method="post" action="/cli/auth/device/approve" enctype="multipart/form-data"><input name="userCode" value="${escapeHtml(input.userCode ?? "")}" placeholder="XXXX-XXXX" autocomplete="off" style="font-size: 1.25rem; letter-spacing: 0.1em;" required> <button type="submit">Connect</button></form>`,
+  );
+});
+
+export const approveCliDeviceAuthHandler = typedHandler<
+  TypedHandlersContext,
+  typeof approveCliDeviceAuthContract
+>(approveCliDeviceAuthContract, async ({ input, session, authKv, webAppUrl }) => {
+  if (!session) {
+    return signInPage(webAppUrl);
+  }
+
+  const userCode = input.userCode.trim().toUpperCase();
+  const deviceCode = await authKv.get(`cli-device-user-code:${userCode}`);
+  const deviceAuth =
+    deviceCode && (await authKv.get<CliDeviceAuth>(`cli-device:${deviceCode}`, { type: "json" }));
+  if (!deviceCode || !deviceAuth) {
+    return htmlPage(
+      "Code not found",
+      "<p>The code is wrong or has expired. Run <code>asyncstatus login --device</code> again.</p>",
+      404,
+    );
+  }
+
+  await authKv.delete(`cli-device-user-code:${userCode}`);
+  await authKv.put(
+    `cli-device:${deviceCode}`,
+    JSON.stringify({ ...deviceAuth, userId: session.user.id } satisfies CliDeviceAuth),
+    { expirationTtl: CLI_DEVICE_CODE_TTL_SECONDS },
+  );
+
+  return htmlPage("CLI connected", "<p>You can close this page and return to your terminal.</p>");
+});
+
+export const pollCliDeviceAuthHandler = typedHandler<
+  TypedHandlersContext,
+  typeof pollCliDeviceAuthContract
+>(pollCliDeviceAuthContract, async ({ input, authKv, auth, req }) => {
+  const key = `cli-device:${input.deviceCode}`;
+  const deviceAuth = await authKv.get<CliDeviceAuth>(key, { type: "json" });
+  if (!deviceAuth) {
+    throw new TypedHandlersError({
+      code: "UNAUTHORIZED",
+      message: "Invalid or expired device code",
+    });
+  }
+
+  if (!deviceAuth.userId) {
+    return { status: "pending" as const };
+  }
+
+  await authKv.delete(key);
+  return {
+    status: "approved" as const,
+    sessionCookie: await createCliSessionCookie(auth, req, deviceAuth.userId),
+  };
+});
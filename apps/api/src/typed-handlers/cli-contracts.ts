@@ -1,4 +1,11 @@
-import { Member, StatusUpdate, StatusUpdateItem, Team, User } from "@asyncstatus/db";
+import {
+  Member,
+  Organization,
+  StatusUpdate,
+  StatusUpdateItem,
+  Team,
+  User,
+} from "@asyncstatus/db";
 import { typedContract } from "@asyncstatus/typed-handlers";
 import { z } from "zod/v4";
 
@@ -7,6 +14,8 @@ export const addCliStatusUpdateItemContract = typedContract(
   z.strictObject({
     type: z.enum(["done", "progress", "blocker"]),
     message: z.string().min(1),
+    // Retries with the same key don't add the item again
+    idempotencyKey: z.string().min(1).max(64).optional(),
   }),
   z.strictObject({
     ...StatusUpdate.shape,
@@ -60,6 +69,26 @@ export const listRecentStatusUpdatesContract = typedContract(
   }),
 );
 
+// Status updates of the days from..to, inclusive and at most 31 days apart
+export const listCliStatusUpdatesInRangeContract = typedContract(
+  "get /cli/status-updates/range",
+  z.strictObject({
+    from: z.iso.date(),
+    to: z.iso.date(),
+  }),
+  z.strictObject({
+    statusUpdates: z.array(
+      z.strictObject({
+        ...StatusUpdate.shape,
+        team: Team.nullable(),
+        items: z.array(StatusUpdateItem),
+        member: z.strictObject({ ...Member.shape, user: User }),
+      }),
+    ),
+    message: z.string(),
+  }),
+);
+
 export const editCliStatusUpdateContract = typedContract(
   "put /cli/status-updates/edit",
   z.strictObject({
@@ -85,6 +114,52 @@ export const editCliStatusUpdateContract = typedContract(
   }),
 );
 
+const cliItemType = z.enum(["done", "progress", "blocker"]);
+
+// Changes items by ID, so unchanged items keep their identity and history
+export const patchCliStatusUpdateContract = typedContract(
+  "patch /cli/status-updates/edit",
+  z.strictObject({
+    changes: z.array(
+      z.discriminatedUnion("op", [
+        z.strictObject({
+          op: z.literal("insert"),
+          content: z.string().min(1),
+          type: cliItemType,
+          order: z.number().int().nonnegative(),
+          idempotencyKey: z.string().min(1).max(64).optional(),
+        }),
+        z.strictObject({
+          op: z.literal("update"),
+          id: z.string().min(1),
+          content: z.string().min(1).optional(),
+          type: cliItemType.optional(),
+          order: z.number().int().nonnegative().optional(),
+        }),
+        z.strictObject({
+          op: z.literal("delete"),
+          id: z.string().min(1),
+        }),
+      ]),
+    ),
+    date: z.string().optional(), // ISO date string, defaults to today
+    mood: z.string().nullable().optional(),
+    notes: z.string().nullable().optional(),
+    // The updatedAt the status update was fetched with, or null if there was
+    // none. The edit fails with CONFLICT when it no longer matches.
+    expectedUpdatedAt: z.iso.datetime({ offset: true }).nullable().optional(),
+  }),
+  z.strictObject({
+    statusUpdate: z.strictObject({
+      ...StatusUpdate.shape,
+      team: Team.nullable(),
+      items: z.array(StatusUpdateItem),
+      member: z.strictObject({ ...Member.shape, user: User }),
+    }),
+    message: z.string(),
+  }),
+);
+
 export const getCliStatusUpdateByDateContract = typedContract(
   "get /cli/status-updates/by-date",
   z.strictObject({ date: z.iso.date() }),
@@ -100,3 +175,46 @@ export const getCliStatusUpdateByDateContract = typedContract(
     message: z.string(),
   }),
 );
+
+export const createCliTokenContract = typedContract(
+  "post /cli/tokens",
+  z.strictObject({
+    name: z.string().trim().min(1).max(64),
+    expiresInDays: z.number().int().min(1).max(365),
+  }),
+  z.strictObject({
+    id: z.string(),
+    token: z.string(),
+    expiresAt: z.string(),
+  }),
+);
+
+const CliToken = z.strictObject({
+  id: z.string(),
+  name: z.string(),
+  createdAt: z.string(),
+  expiresAt: z.string(),
+});
+
+export const listCliTokensContract = typedContract(
+  "get /cli/tokens",
+  z.strictObject({}),
+  z.strictObject({ tokens: z.array(CliToken) }),
+);
+
+export const revokeCliTokenContract = typedContract(
+  "delete /cli/tokens/:tokenId",
+  z.strictObject({ tokenId: z.string().min(1) }),
+  z.strictObject({ success: z.boolean() }),
+);
+
+export const getCliWhoamiContract = typedContract(
+  "get /cli/whoami",
+  z.strictObject({}),
+  z.strictObject({
+    user: User,
+    organization: Organization,
+    member: Member,
+    teams: z.array(Team),
+  }),
+);
@@ -3,16 +3,122 @@ import * as schema from "@asyncstatus/db";
 import { TypedHandlersError, typedHandler } from "@asyncstatus/typed-handlers";
 import { generateId } from "better-auth";
 import { and, desc, eq, gte, lte } from "drizzle-orm";
-import type { TypedHandlersContextWithOrganization } from "../lib/env";
+import { decodeJwt } from "jose";
+import type {
+  TypedHandlersContextWithOrganization,
+  TypedHandlersContextWithSession,
+} from "../lib/env";
 import {
   addCliStatusUpdateItemContract,
+  createCliTokenContract,
   editCliStatusUpdateContract,
   getCliStatusUpdateByDateContract,
+  getCliWhoamiContract,
+  listCliStatusUpdatesInRangeContract,
+  listCliTokensContract,
   listRecentStatusUpdatesContract,
+  patchCliStatusUpdateContract,
+  revokeCliTokenContract,
   showCurrentStatusUpdateContract,
   undoLastCliStatusUpdateItemContract,
 } from "./cli-contracts";
-import { requiredActiveOrganization, requiredJwt } from "./middleware";
+import {
+  cliTokenKey,
+  requiredActiveOrganization,
+  requiredJwt,
+  requiredSession,
+} from "./middleware";
+
+// The CLI sends the IANA timezone its days are counted in, so "today" and the
+// day of a date match the user's calendar. Older CLIs count days in UTC.
+const cliTimezoneHeader = "x-asyncstatus-timezone";
+
+function getCliTimezone(req: Request): string | null {
+  const timezone = req.headers.get(cliTimezoneHeader);
+  if (!timezone) {
+    return null;
+  }
+
+  try {
+    new Intl.DateTimeFormat("en-US", { timeZone: timezone });
+  } catch {
+    throw new TypedHandlersError({
+      code: "BAD_REQUEST",
+      message: `Unknown timezone: ${timezone}`,
+    });
+  }
+  return timezone;
+}
+
+// Start and end of a day in the given timezone, today if no date is given
+function getCliDayRange(timezone: string | null, date?: string) {
+  const tz = timezone ?? "UTC";
+  const day = date ? dayjs.tz(date, tz) : dayjs().tz(tz);
+  return {
+    effectiveFromStartOfDay: day.startOf("day").toDate(),
+    effectiveToEndOfDay: day.endOf("day").toDate(),
+  };
+}
+
+type CliItemType = "done" | "progress" | "blocker";
+
+function getCliItemType(item: { isBlocker: boolean; isInProgress: boolean }): CliItemType {
+  return item.isBlocker ? "blocker" : item.isInProgress ? "progress" : "done";
+}
+
+// The web editor reads status updates from editorJson, so CLI edits rebuild it.
+// Mood and notes left undefined keep the paragraphs of the existing document.
+// Only done items are checked, as the edit handler always wrote them: adding
+// items used to check blockers too, so the same blocker showed differently
+// depending on whether it was last added or edited from the CLI.
+function buildCliEditorJson(
+  date: Date,
+  items: { content: string; type: CliItemType }[],
+  mood: string | null | undefined,
+  notes: string | null | undefined,
+  existingEditorJson: unknown,
+) {
+  return {
+    type: "doc",
+    content: [
+      {
+        type: "statusUpdateHeading",
+        attrs: { date: date.toISOString() },
+      },
+      {
+        type: "blockableTodoList",
+        content: items.map((item) => ({
+          type: "blockableTodoListItem",
+          attrs: {
+            checked: item.type === "done",
+            blocked: item.type === "blocker",
+          },
+          content: [{ type: "paragraph", content: [{ type: "text", text: item.content }] }],
+        })),
+      },
+      { type: "notesHeading" },
+      {
+        type: "paragraph",
+        content:
+          notes === null
+            ? []
+            : notes === undefined
+              ? ((existingEditorJson as any)?.content?.[3]?.content ?? [])
+              : [{ type: "text", text: notes }],
+      },
+      { type: "moodHeading" },
+      {
+        type: "paragraph",
+        content:
+          mood === null
+            ? []
+            : mood === undefined
+              ? ((existingEditorJson as any)?.content?.[5]?.content ?? [])
+              : [{ type: "text", text: mood }],
+      },
+    ],
+  };
+}
 
 export const addCliStatusUpdateItemHandler = typedHandler<
   TypedHandlersContextWithOrganization,
@@ -21,14 +127,13 @@ export const addCliStatusUpdateItemHandler = typedHandler<
   addCliStatusUpdateItemContract,
   requiredJwt,
   requiredActiveOrganization,
-  async ({ db, input, session, organization, member }) => {
-    const { type, message } = input;
+  async ({ db, req, input, session, organization, member }) => {
+    const { type, message, idempotencyKey } = input;
 
     // Get current date in user's timezone for the status update
-    const now = dayjs().utc();
-    const effectiveFromStartOfDay = now.startOf("day").toDate();
-    const effectiveToEndOfDay = now.endOf("day").toDate();
-    const nowDate = now.toDate();
+    const timezone = getCliTimezone(req);
+    const { effectiveFromStartOfDay, effectiveToEndOfDay } = getCliDayRange(timezone);
+    const nowDate = dayjs().utc().toDate();
 
     const statusUpdate = await db.transaction(async (tx) => {
       // Check if a status update already exists for this member on today's date
@@ -73,7 +178,7 @@ export const addCliStatusUpdateItemHandler = typedHandler<
           emoji: null,
           notes: null,
           isDraft: false,
-          timezone: session.user.timezone || "UTC",
+          timezone: timezone ?? (session.user.timezone || "UTC"),
           createdAt: nowDate,
           updatedAt: nowDate,
         });
@@ -96,17 +201,25 @@ export const addCliStatusUpdateItemHandler = typedHandler<
       const isInProgress = type === "progress";
       const isBlocker = type === "blocker";
 
+      // A retried request whose item was already added leaves it alone
+      const alreadyAdded =
+        idempotencyKey !== undefined &&
+        existingStatusUpdate?.items.some((item) => item.idempotencyKey === idempotencyKey);
+
       // Add the new status update item
-      await tx.insert(schema.statusUpdateItem).values({
-        id: generateId(),
-        statusUpdateId,
-        content: message,
-        isBlocker,
-        isInProgress,
-        order: nextOrder,
-        createdAt: nowDate,
-        updatedAt: nowDate,
-      });
+      if (!alreadyAdded) {
+        await tx.insert(schema.statusUpdateItem).values({
+          id: generateId(),
+          statusUpdateId,
+          content: message,
+          isBlocker,
+          isInProgress,
+          order: nextOrder,
+          idempotencyKey,
+          createdAt: nowDate,
+          updatedAt: nowDate,
+        });
+      }
 
       // Get all items after adding the new one to update editorJson
       const allItems = await tx.query.statusUpdateItem.findMany({
@@ -115,33 +228,13 @@ export const addCliStatusUpdateItemHandler = typedHandler<
       });
 
       // Update editorJson with all items
-      const nextEditorJson = {
-        type: "doc",
-        content: [
-          {
-            type: "statusUpdateHeading",
-            attrs: { date: effectiveFromStartOfDay.toISOString() },
-          },
-          {
-            type: "blockableTodoList",
-            content: allItems.map((item) => ({
-              type: "blockableTodoListItem",
-              attrs: { checked: !item.isInProgress, blocked: item.isBlocker },
-              content: [{ type: "paragraph", content: [{ type: "text", text: item.content }] }],
-            })),
-          },
-          { type: "notesHeading" },
-          {
-            type: "paragraph",
-            content: (existingStatusUpdate?.editorJson as any)?.content?.[3]?.content ?? [],
-          },
-          { type: "moodHeading" },
-          {
-            type: "paragraph",
-            content: (existingStatusUpdate?.editorJson as any)?.content?.[5]?.content ?? [],
-          },
-        ],
-      };
+      const nextEditorJson = buildCliEditorJson(
+        effectiveFromStartOfDay,
+        allItems.map((item) => ({ content: item.content, type: getCliItemType(item) })),
+        undefined,
+        undefined,
+        existingStatusUpdate?.editorJson,
+      );
 
       // Update the status update with the new editorJson
       await tx
@@ -182,10 +275,8 @@ export const undoLastCliStatusUpdateItemHandler = typedHandler<
   undoLastCliStatusUpdateItemContract,
   requiredJwt,
   requiredActiveOrganization,
-  async ({ db, organization, member }) => {
-    const now = dayjs().utc();
-    const effectiveFromStartOfDay = now.startOf("day").toDate();
-    const effectiveToEndOfDay = now.endOf("day").toDate();
+  async ({ db, req, organization, member }) => {
+    const { effectiveFromStartOfDay, effectiveToEndOfDay } = getCliDayRange(getCliTimezone(req));
 
     const result = await db.transaction(async (tx) => {
       const statusUpdate = await tx.query.statusUpdate.findFirst({
@@ -299,10 +390,8 @@ export const showCurrentStatusUpdateHandler = typedHandler<
   showCurrentStatusUpdateContract,
   requiredJwt,
   requiredActiveOrganization,
-  async ({ db, organization, member }) => {
-    const now = dayjs().utc();
-    const effectiveFromStartOfDay = now.startOf("day").toDate();
-    const effectiveToEndOfDay = now.endOf("day").toDate();
+  async ({ db, req, organization, member }) => {
+    const { effectiveFromStartOfDay, effectiveToEndOfDay } = getCliDayRange(getCliTimezone(req));
 
     const statusUpdate = await db.query.statusUpdate.findFirst({
       where: and(
@@ -341,10 +430,10 @@ export const listRecentStatusUpdatesHandler = typedHandler<
   listRecentStatusUpdatesContract,
   requiredJwt,
   requiredActiveOrganization,
-  async ({ db, organization, member, input }) => {
+  async ({ db, req, organization, member, input }) => {
     const { days = 1 } = input;
 
-    const now = dayjs().utc();
+    const now = dayjs().tz(getCliTimezone(req) ?? "UTC");
     const startDate = now
       .subtract(days as number, "day")
       .startOf("day")
@@ -376,6 +465,61 @@ export const listRecentStatusUpdatesHandler = typedHandler<
   },
 );
 
+// The longest range a single request may cover, clients split longer ranges
+const maxCliRangeDays = 31;
+
+export const listCliStatusUpdatesInRangeHandler = typedHandler<
+  TypedHandlersContextWithOrganization,
+  typeof listCliStatusUpdatesInRangeContract
+>(
+  listCliStatusUpdatesInRangeContract,
+  requiredJwt,
+  requiredActiveOrganization,
+  async ({ db, req, organization, member, input }) => {
+    const { from, to } = input;
+
+    const timezone = getCliTimezone(req);
+    const { effectiveFromStartOfDay } = getCliDayRange(timezone, from);
+    const { effectiveToEndOfDay } = getCliDayRange(timezone, to);
+
+    const days = dayjs(to).diff(dayjs(from), "day") + 1;
+    if (days < 1) {
+      throw new TypedHandlersError({
+        code: "BAD_REQUEST",
+        message: `The range ends before it starts: ${from} to ${to}`,
+      });
+    }
+    if (days > maxCliRangeDays) {
+      throw new TypedHandlersError({
+        code: "BAD_REQUEST",
+        message: `A range can cover at most ${maxCliRangeDays} days, got ${days}`,
+      });
+    }
+
+    const statusUpdates = await db.query.statusUpdate.findMany({
+      where: and(
+        eq(schema.statusUpdate.memberId, member.id),
+        eq(schema.statusUpdate.organizationId, organization.id),
+        gte(schema.statusUpdate.effectiveFrom, effectiveFromStartOfDay),
+        lte(schema.statusUpdate.effectiveTo, effectiveToEndOfDay),
+      ),
+      with: {
+        member: { with: { user: true } },
+        team: true,
+        items: {
+          orderBy: (items) => [items.order],
+        },
+      },
+      orderBy: (statusUpdate) => [desc(statusUpdate.effectiveFrom)],
+    });
+
+    return {
+      statusUpdates,
+      message: `Found ${statusUpdates.length} status update(s) from ${from} to ${to}`,
+    };
+  },
+);
+
 export const editCliStatusUpdateHandler = typedHandler<
   TypedHandlersContextWithOrganization,
   typeof editCliStatusUpdateContract
@@ -383,13 +527,12 @@ export const editCliStatusUpdateHandler = typedHandler<
   editCliStatusUpdateContract,
   requiredJwt,
   requiredActiveOrganization,
-  async ({ db, input, session, organization, member }) => {
+  async ({ db, req, input, session, organization, member }) => {
     const { items, date, mood, notes } = input;
 
     // Parse the target date or use today
-    const targetDate = date ? dayjs.utc(date) : dayjs().utc();
-    const effectiveFromStartOfDay = targetDate.startOf("day").toDate();
-    const effectiveToEndOfDay = targetDate.endOf("day").toDate();
+    const timezone = getCliTimezone(req);
+    const { effectiveFromStartOfDay, effectiveToEndOfDay } = getCliDayRange(timezone, date);
     const nowDate = dayjs().utc().toDate();
 
     const statusUpdate = await db.transaction(async (tx) => {
@@ -457,7 +600,7 @@ export const editCliStatusUpdateHandler = typedHandler<
           emoji: null,
           notes: notes || null,
           isDraft: false,
-          timezone: session.user.timezone || "UTC",
+          timezone: timezone ?? (session.user.timezone || "UTC"),
           createdAt: nowDate,
           updatedAt: nowDate,
         });
@@ -480,46 +623,13 @@ export const editCliStatusUpdateHandler = typedHandler<
       }
 
       // Update editorJson with all items
-      const nextEditorJson = {
-        type: "doc",
-        content: [
-          {
-            type: "statusUpdateHeading",
-            attrs: { date: effectiveFromStartOfDay.toISOString() },
-          },
-          {
-            type: "blockableTodoList",
-            content: items.map((item) => ({
-              type: "blockableTodoListItem",
-              attrs: {
-                checked: item.type === "done",
-                blocked: item.type === "blocker",
-              },
-              content: [{ type: "paragraph", content: [{ type: "text", text: item.content }] }],
-            })),
-          },
-          { type: "notesHeading" },
-          {
-            type: "paragraph",
-            content:
-              notes === null
-                ? []
-                : notes === undefined
-                  ? ((existingStatusUpdate?.editorJson as any)?.content?.[3]?.content ?? [])
-                  : [{ type: "text", text: notes }],
-          },
-          { type: "moodHeading" },
-          {
-            type: "paragraph",
-            content:
-              mood === null
-                ? []
-                : mood === undefined
-                  ? ((existingStatusUpdate?.editorJson as any)?.content?.[5]?.content ?? [])
-                  : [{ type: "text", text: mood }],
-          },
-        ],
-      };
+      const nextEditorJson = buildCliEditorJson(
+        effectiveFromStartOfDay,
+        items,
+        mood,
+        notes,
+        existingStatusUpdate?.editorJson,
+      );
 
       // Update the status update with the new editorJson
       await tx
@@ -556,6 +666,194 @@ export const editCliStatusUpdateHandler = typedHandler<
   },
 );
 
+export const patchCliStatusUpdateHandler = typedHandler<
+  TypedHandlersContextWithOrganization,
+  typeof patchCliStatusUpdateContract
+>(
+  patchCliStatusUpdateContract,
+  requiredJwt,
+  requiredActiveOrganization,
+  async ({ db, req, input, session, organization, member }) => {
+    const { changes, date, mood, notes, expectedUpdatedAt } = input;
+
+    const timezone = getCliTimezone(req);
+    const { effectiveFromStartOfDay, effectiveToEndOfDay } = getCliDayRange(timezone, date);
+    const nowDate = dayjs().utc().toDate();
+
+    const statusUpdate = await db.transaction(async (tx) => {
+      const existingStatusUpdate = await tx.query.statusUpdate.findFirst({
+        where: and(
+          eq(schema.statusUpdate.memberId, member.id),
+          eq(schema.statusUpdate.organizationId, organization.id),
+          gte(schema.statusUpdate.effectiveFrom, effectiveFromStartOfDay),
+          lte(schema.statusUpdate.effectiveTo, effectiveToEndOfDay),
+        ),
+        with: { items: true },
+      });
+
+      // Refuse to apply changes made against an outdated copy
+      if (expectedUpdatedAt !== undefined) {
+        const currentUpdatedAt = existingStatusUpdate?.updatedAt.getTime() ?? null;
+        const fetchedUpdatedAt =
+          expectedUpdatedAt === null ? null : new Date(expectedUpdatedAt).getTime();
+        if (currentUpdatedAt !== fetchedUpdatedAt) {
+          throw new TypedHandlersError({
+            code: "CONFLICT",
+            message: "Status update was changed since it was fetched",
+          });
+        }
+      }
+
+      // Updates and deletes may only touch items of this status update
+      const existingItemIds = new Set(existingStatusUpdate?.items.map((item) => item.id) ?? []);
+      for (const change of changes) {
+        if (change.op !== "insert" && !existingItemIds.has(change.id)) {
+          throw new TypedHandlersError({
+            code: "BAD_REQUEST",
+            message: `Unknown status update item: ${change.id}`,
+          });
+        }
+      }
+
+      let statusUpdateId: string;
+
+      if (existingStatusUpdate) {
+        statusUpdateId = existingStatusUpdate.id;
+
+        const updateData: {
+          updatedAt: Date;
+          mood?: string | null;
+          notes?: string | null;
+        } = {
+          updatedAt: nowDate,
+        };
+        if (mood !== undefined) {
+          updateData.mood = mood;
+        }
+        if (notes !== undefined) {
+          updateData.notes = notes;
+        }
+
+        await tx
+          .update(schema.statusUpdate)
+          .set(updateData)
+          .where(eq(schema.statusUpdate.id, statusUpdateId));
+      } else {
+        statusUpdateId = generateId();
+
+        await tx.insert(schema.statusUpdate).values({
+          id: statusUpdateId,
+          memberId: member.id,
+          organizationId: organization.id,
+          teamId: null,
+          editorJson: null,
+          effectiveFrom: effectiveFromStartOfDay,
+          effectiveTo: effectiveToEndOfDay,
+          mood: mood || null,
+          emoji: null,
+          notes: notes || null,
+          isDraft: false,
+          timezone: timezone ?? (session.user.timezone || "UTC"),
+          createdAt: nowDate,
+          updatedAt: nowDate,
+        });
+      }
+
+      for (const change of changes) {
+        if (change.op === "delete") {
+          await tx
+            .delete(schema.statusUpdateItem)
+            .where(
+              and(
+                eq(schema.statusUpdateItem.id, change.id),
+                eq(schema.statusUpdateItem.statusUpdateId, statusUpdateId),
+              ),
+            );
+        } else if (change.op === "update") {
+          await tx
+            .update(schema.statusUpdateItem)
+            .set({
+              ...(change.content !== undefined && { content: change.content }),
+              ...(change.type !== undefined && {
+                isBlocker: change.type === "blocker",
+                isInProgress: change.type === "progress",
+              }),
+              ...(change.order !== undefined && { order: change.order }),
+              updatedAt: nowDate,
+            })
+            .where(
+              and(
+                eq(schema.statusUpdateItem.id, change.id),
+                eq(schema.statusUpdateItem.statusUpdateId, statusUpdateId),
+              ),
+            );
+        } else if (
+          change.idempotencyKey === undefined ||
+          !existingStatusUpdate?.items.some((item) => item.idempotencyKey === change.idempotencyKey)
+        ) {
+          // A retried insert whose item was already added is skipped
+          await tx.insert(schema.statusUpdateItem).values({
+            id: generateId(),
+            statusUpdateId,
+            content: change.content,
+            isBlocker: change.type === "blocker",
+            isInProgress: change.type === "progress",
+            order: change.order,
+            idempotencyKey: change.idempotencyKey,
+            createdAt: nowDate,
+            updatedAt: nowDate,
+          });
+        }
+      }
+
+      const items = await tx.query.statusUpdateItem.findMany({
+        where: eq(schema.statusUpdateItem.statusUpdateId, statusUpdateId),
+        orderBy: (items) => [items.order],
+      });
+
+      await tx
+        .update(schema.statusUpdate)
+        .set({
+          editorJson: buildCliEditorJson(
+            effectiveFromStartOfDay,
+            items.map((item) => ({ content: item.content, type: getCliItemType(item) })),
+            mood,
+            notes,
+            existingStatusUpdate?.editorJson,
+          ),
+          updatedAt: nowDate,
+          isDraft: false,
+        })
+        .where(eq(schema.statusUpdate.id, statusUpdateId));
+
+      const result = await tx.query.statusUpdate.findFirst({
+        where: eq(schema.statusUpdate.id, statusUpdateId),
+        with: {
+          member: { with: { user: true } },
+          team: true,
+          items: {
+            orderBy: (items) => [items.order],
+          },
+        },
+      });
+
+      if (!result) {
+        throw new TypedHandlersError({
+          code: "INTERNAL_SERVER_ERROR",
+          message: "Failed to create or update status update",
+        });
+      }
+
+      return result;
+    });
+
+    return {
+      statusUpdate,
+      message: "Status update edited successfully",
+    };
+  },
+);
+
 export const getCliStatusUpdateByDateHandler = typedHandler<
   TypedHandlersContextWithOrganization,
   typeof getCliStatusUpdateByDateContract
@@ -563,13 +861,14 @@ export const getCliStatusUpdateByDateHandler = typedHandler<
   getCliStatusUpdateByDateContract,
   requiredJwt,
   requiredActiveOrganization,
-  async ({ db, organization, member, input }) => {
+  async ({ db, req, organization, member, input }) => {
     const { date } = input;
 
     // Parse the target date
-    const targetDate = dayjs.utc(date);
-    const effectiveFromStartOfDay = targetDate.startOf("day").toDate();
-    const effectiveToEndOfDay = targetDate.endOf("day").toDate();
+    const { effectiveFromStartOfDay, effectiveToEndOfDay } = getCliDayRange(
+      getCliTimezone(req),
+      date,
+    );
 
     // Find status update for this member on the target date
     const statusUpdate = await db.query.statusUpdate.findFirst({
@@ -596,3 +895,123 @@ export const getCliStatusUpdateByDateHandler = typedHandler<
     };
   },
 );
+
+interface CliTokenRecord {
+  name: string;
+  createdAt: string;
+  expiresAt: string;
+}
+
+// Long-lived tokens for automation need the web session, so a token can't be
+// used to mint further tokens and outlive the session it came from
+export const createCliTokenHandler = typedHandler<
+  TypedHandlersContextWithSession,
+  typeof createCliTokenContract
+>(createCliTokenContract, requiredSession, async ({ auth, authKv, input, session }) => {
+  // The session token would let anyone holding the JWT act as the web session
+  const { token: _sessionToken, ...tokenSession } = session.session;
+
+  const id = generateId();
+  const { token } = await auth.api.signJWT({
+    body: {
+      payload: {
+        session: tokenSession,
+        user: { ...session.user, activeOrganizationSlug: session.session.activeOrganizationSlug },
+        tokenName: input.name,
+        jti: id,
+      },
+      overrideOptions: { jwt: { expirationTime: `${input.expiresInDays}d` } },
+    },
+  });
+
+  const { exp } = decodeJwt(token);
+  if (!exp) {
+    throw new TypedHandlersError({
+      code: "INTERNAL_SERVER_ERROR",
+      message: "Failed to create token",
+    });
+  }
+
+  // The record lives as long as the token, requiredJwt rejects tokens without one
+  const record: CliTokenRecord = {
+    name: input.name,
+    createdAt: new Date().toISOString(),
+    expiresAt: new Date(exp * 1000).toISOString(),
+  };
+  await authKv.put(cliTokenKey(session.user.id, id), JSON.stringify(record), {
+    expirationTtl: input.expiresInDays * 24 * 60 * 60,
+    metadata: record,
+  });
+
+  return { id, token, expiresAt: record.expiresAt };
+});
+
+export const listCliTokensHandler = typedHandler<
+  TypedHandlersContextWithSession,
+  typeof listCliTokensContract
+>(listCliTokensContract, requiredSession, async ({ authKv, session }) => {
+  const prefix = cliTokenKey(session.user.id, "");
+  const tokens: Array<CliTokenRecord & { id: string }> = [];
+  let cursor: string | undefined;
+  do {
+    const page = await authKv.list<CliTokenRecord>({ prefix, cursor });
+    for (const key of page.keys) {
+      if (key.metadata) {
+        tokens.push({ id: key.name.slice(prefix.length), ...key.metadata });
+      }
+    }
+    cursor = page.list_complete ? undefined : page.cursor;
+  } while (cursor);
+
+  tokens.sort((a, b) => a.createdAt.localeCompare(b.createdAt));
+  return { tokens };
+});
+
+export const revokeCliTokenHandler = typedHandler<
+  TypedHandlersContextWithSession,
+  typeof revokeCliTokenContract
+>(revokeCliTokenContract, requiredSession, async ({ authKv, input, session }) => {
+  const key = cliTokenKey(session.user.id, input.tokenId);
+  if (!(await authKv.get(key))) {
+    throw new TypedHandlersError({
+      code: "NOT_FOUND",
+      message: "Token not found",
+    });
+  }
+
+  await authKv.delete(key);
+  return { success: true };
+});
+
+export const getCliWhoamiHandler = typedHandler<
+  TypedHandlersContextWithOrganization,
+  typeof getCliWhoamiContract
+>(
+  getCliWhoamiContract,
+  requiredJwt,
+  requiredActiveOrganization,
+  async ({ db, session, organization, member }) => {
+    // The token carries the user as of when it was issued, so read the current one
+    const user = await db.query.user.findFirst({
+      where: eq(schema.user.id, session.user.id),
+    });
+    if (!user) {
+      throw new TypedHandlersError({
+        code: "NOT_FOUND",
+        message: "User not found",
+      });
+    }
+
+    const teamMemberships = await db.query.teamMembership.findMany({
+      where: eq(schema.teamMembership.memberId, member.id),
+      with: { team: true },
+    });
+
+    return {
+      user,
+      organization,
+      member,
+      teams: teamMemberships.map((teamMembership) => teamMembership.team),
+    };
+  },
+);
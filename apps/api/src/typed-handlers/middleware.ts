@@ -38,6 +38,14 @@ interface JWTPayload {
   aud: string;
   exp: number;
   sub: string;
+  jti?: string;
+  tokenName?: string;
+}
+
+// Tokens created for automation are valid while their record exists, so
+// revoking one deletes it
+export function cliTokenKey(userId: string, tokenId: string) {
+  return `cli-token:${userId}:${tokenId}`;
 }
 
 export const requiredSession = typedMiddleware<TypedHandlersContextWithSession>(
@@ -53,7 +61,7 @@ export const requiredSession = typedMiddleware<TypedHandlersContextWithSession>(
 );
 
 export const requiredJwt = typedMiddleware<TypedHandlersContextWithSession>(
-  async ({ req, set, betterAuthUrl, auth }, next) => {
+  async ({ req, set, betterAuthUrl, auth, authKv }, next) => {
     // Extract JWT token from Authorization header
     const authHeader = req.headers.get("authorization");
     if (!authHeader || !authHeader.startsWith("Bearer ")) {
@@ -100,6 +108,16 @@ export const requiredJwt = typedMiddleware<TypedHandlersContextWithSession>(
         });
       }
 
+      if (
+        jwtPayload.tokenName !== undefined &&
+        (!jwtPayload.jti || !(await authKv.get(cliTokenKey(jwtUser.id, jwtPayload.jti))))
+      ) {
+        throw new TypedHandlersError({
+          code: "UNAUTHORIZED",
+          message: "Token has been revoked",
+        });
+      }
+
       const session = {
         session: {
           ...jwtSession,
@@ -163,9 +181,15 @@ export const requiredOrganization = typedMiddleware<TypedHandlersContextWithOrga
   },
 );
 
+// The CLI can act on another organization for a single request by sending
+// its id or slug in this header, membership is checked like for the active one.
+const organizationOverrideHeader = "x-asyncstatus-organization";
+
 export const requiredActiveOrganization = typedMiddleware<TypedHandlersContextWithOrganization>(
-  async ({ db, set, session }, next) => {
-    if (!session.session.activeOrganizationSlug) {
+  async ({ req, db, set, session }, next) => {
+    const idOrSlug =
+      req.headers.get(organizationOverrideHeader) || session.session.activeOrganizationSlug;
+    if (!idOrSlug) {
       throw new TypedHandlersError({
         code: "BAD_REQUEST",
         message: "No active organization found.",
@@ -173,10 +197,7 @@ export const requiredActiveOrganization = typedMiddleware<TypedHandlersContextWi
     }
 
     const org = await db.query.organization.findFirst({
-      where: or(
-        eq(organization.id, session.session.activeOrganizationSlug),
-        eq(organization.slug, session.session.activeOrganizationSlug),
-      ),
+      where: or(eq(organization.id, idOrSlug), eq(organization.slug, idOrSlug)),
       with: {
         members: {
           limit: 1,
@@ -0,0 +1,559 @@
+// Package client is a typed Go client for the AsyncStatus CLI API.
+//
+// It is used by the asyncstatus command itself and can be imported by other
+// Go tools, for example bots that post status updates:
+//
+//	c := client.New(client.DefaultBaseURL, token)
+//	update, err := c.AddStatusUpdateItem(ctx, client.ItemTypeDone, "shipped the release")
+//	if errors.Is(err, client.ErrUnauthorized) {
+//		// token expired, log in again
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultBaseURL is the production AsyncStatus API endpoint
+const DefaultBaseURL = "https://api.asyncstatus.com"
+
+// DefaultTimeout is the request timeout used when no custom HTTP client is set
+const DefaultTimeout = 30 * time.Second
+
+// sharedTransport is reused by every Client so connections are pooled
+// across requests and across clients
+var sharedTransport = http.DefaultTransport.(*http.Transport).Clone()
+
+// OrganizationHeader selects the organization a request acts on instead of
+// the user's active organization
+const OrganizationHeader = "X-AsyncStatus-Organization"
+
+// TimezoneHeader carries the IANA zone whose days status update requests
+// refer to; without it the API counts days in UTC
+const TimezoneHeader = "X-AsyncStatus-Timezone"
+
+// MaxRangeDays is the longest range ListStatusUpdatesInRange accepts
+const MaxRangeDays = 31
+
+// TokenRefresher returns a new token when the server rejects the current one
+type TokenRefresher func(ctx context.Context) (string, error)
+
+// credentials holds the token of a Client; copies made by ForOrganization
+// share it so a refresh is seen by all of them
+type credentials struct {
+	mu      sync.Mutex
+	token   string
+	refresh TokenRefresher
+}
+
+// current returns the token to send
+func (c *credentials) current() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// refreshAfter replaces a token the server rejected. If another request
+// already replaced it, the newer token is returned without refreshing again.
+func (c *credentials) refreshAfter(ctx context.Context, rejected string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != rejected {
+		return c.token, nil
+	}
+
+	token, err := c.refresh(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	return token, nil
+}
+
+// Client talks to the AsyncStatus API on behalf of a single user
+type Client struct {
+	baseURL       string
+	creds         *credentials
+	sessionCookie string
+	organization  string
+	timezone      string
+	userAgent     string
+	httpClient    *http.Client
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient replaces the default HTTP client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout sets the timeout of requests. It applies to a copy of the HTTP
+// client, so one passed to WithHTTPClient is left as it is.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		httpClient := *c.httpClient
+		httpClient.Timeout = timeout
+		c.httpClient = &httpClient
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithSessionCookie sets the web session cookie, needed by the organization
+// endpoints and to issue new tokens
+func WithSessionCookie(cookie string) Option {
+	return func(c *Client) {
+		c.sessionCookie = cookie
+	}
+}
+
+// WithTokenRefresher sets a function that is called once when the server
+// rejects the token as unauthorized; the request is retried with the new token
+func WithTokenRefresher(refresh TokenRefresher) Option {
+	return func(c *Client) {
+		c.creds.refresh = refresh
+	}
+}
+
+// WithOrganization makes status update requests act on the organization with
+// the given slug instead of the user's active organization
+func WithOrganization(slug string) Option {
+	return func(c *Client) {
+		c.organization = slug
+	}
+}
+
+// WithTimezone makes status update requests count days in the IANA zone
+// with the given name, such as "today" and the day of a date
+func WithTimezone(name string) Option {
+	return func(c *Client) {
+		c.timezone = name
+	}
+}
+
+// New creates a client for the API at baseURL authenticated with the given JWT token
+func New(baseURL, token string, opts ...Option) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	c := &Client{
+		baseURL:   baseURL,
+		creds:     &credentials{token: token},
+		userAgent: "asyncstatus-go-client",
+		httpClient: &http.Client{
+			Transport: sharedTransport,
+			Timeout:   DefaultTimeout,
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// ForOrganization returns a copy of the client acting on the organization with
+// the given slug, or on the user's active organization if slug is empty
+func (c *Client) ForOrganization(slug string) *Client {
+	clone := *c
+	clone.organization = slug
+	return &clone
+}
+
+// BaseURL returns the API base URL the client talks to
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Token returns the JWT token the client currently sends, which changes after a refresh
+func (c *Client) Token() string {
+	return c.creds.current()
+}
+
+// AddStatusUpdateItem appends an item to today's status update, creating it if needed
+func (c *Client) AddStatusUpdateItem(ctx context.Context, itemType ItemType, message string) (*StatusUpdate, error) {
+	return c.AddStatusUpdateItemOnce(ctx, itemType, message, "")
+}
+
+// AddStatusUpdateItemOnce adds an item to today's status update unless it
+// already has an item added with idempotencyKey, so the request can be retried
+// safely. An empty key always adds the item.
+func (c *Client) AddStatusUpdateItemOnce(ctx context.Context, itemType ItemType, message, idempotencyKey string) (*StatusUpdate, error) {
+	payload := AddStatusUpdateItemRequest{
+		Type:           itemType,
+		Message:        message,
+		IdempotencyKey: idempotencyKey,
+	}
+
+	var statusUpdate StatusUpdate
+	if err := c.do(ctx, http.MethodPost, "/cli/status-updates", nil, payload, &statusUpdate); err != nil {
+		return nil, err
+	}
+
+	return &statusUpdate, nil
+}
+
+// UndoLastStatusUpdateItem removes the most recent item from today's status update
+func (c *Client) UndoLastStatusUpdateItem(ctx context.Context) (*UndoResponse, error) {
+	var response UndoResponse
+	if err := c.do(ctx, http.MethodDelete, "/cli/status-updates/last", nil, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// CurrentStatusUpdate returns today's status update, or nil if there is none
+func (c *Client) CurrentStatusUpdate(ctx context.Context) (*StatusUpdate, error) {
+	var response StatusUpdateResponse
+	if err := c.do(ctx, http.MethodGet, "/cli/status-updates/current", nil, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.StatusUpdate, nil
+}
+
+// StatusUpdateByDate returns the status update for a YYYY-MM-DD date, or nil if there is none
+func (c *Client) StatusUpdateByDate(ctx context.Context, date string) (*StatusUpdate, error) {
+	query := url.Values{"date": {date}}
+
+	var response StatusUpdateResponse
+	if err := c.do(ctx, http.MethodGet, "/cli/status-updates/by-date", query, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.StatusUpdate, nil
+}
+
+// ListRecentStatusUpdates returns the status updates from the past number of days
+func (c *Client) ListRecentStatusUpdates(ctx context.Context, days int) (*ListStatusUpdatesResponse, error) {
+	query := url.Values{"days": {strconv.Itoa(days)}}
+
+	var response ListStatusUpdatesResponse
+	if err := c.do(ctx, http.MethodGet, "/cli/status-updates/recent", query, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ListStatusUpdatesInRange returns the status updates of the YYYY-MM-DD days from
+// through to, newest first. The range may cover at most MaxRangeDays days.
+func (c *Client) ListStatusUpdatesInRange(ctx context.Context, from, to string) (*ListStatusUpdatesResponse, error) {
+	query := url.Values{"from": {from}, "to": {to}}
+
+	var response ListStatusUpdatesResponse
+	if err := c.do(ctx, http.MethodGet, "/cli/status-updates/range", query, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// EditStatusUpdate replaces the items, mood and notes of the status update for req.Date
+func (c *Client) EditStatusUpdate(ctx context.Context, req *EditStatusUpdateRequest) (*StatusUpdate, error) {
+	var response StatusUpdateResponse
+	if err := c.do(ctx, http.MethodPut, "/cli/status-updates/edit", nil, req, &response); err != nil {
+		return nil, err
+	}
+
+	return response.StatusUpdate, nil
+}
+
+// PatchStatusUpdate applies item changes and sets the mood and notes of the
+// status update for req.Date
+func (c *Client) PatchStatusUpdate(ctx context.Context, req *PatchStatusUpdateRequest) (*StatusUpdate, error) {
+	var response StatusUpdateResponse
+	if err := c.do(ctx, http.MethodPatch, "/cli/status-updates/edit", nil, req, &response); err != nil {
+		return nil, err
+	}
+
+	return response.StatusUpdate, nil
+}
+
+// Whoami returns the logged in user, their role and teams in the active organization
+func (c *Client) Whoami(ctx context.Context) (*Whoami, error) {
+	var response Whoami
+	if err := c.do(ctx, http.MethodGet, "/cli/whoami", nil, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ListOrganizations returns the organizations the user is a member of.
+// It requires a session cookie.
+func (c *Client) ListOrganizations(ctx context.Context) ([]OrganizationMembership, error) {
+	var response []OrganizationMembership
+	if err := c.do(ctx, http.MethodGet, "/organizations/member", nil, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Organization returns an organization by ID or slug with the user's membership.
+// It requires a session cookie.
+func (c *Client) Organization(ctx context.Context, idOrSlug string) (*OrganizationMembership, error) {
+	var response OrganizationMembership
+	if err := c.do(ctx, http.MethodGet, "/organizations/"+url.PathEscape(idOrSlug), nil, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// SetActiveOrganization makes an organization the user's active one.
+// It requires a session cookie; tokens issued before the switch keep the old organization.
+func (c *Client) SetActiveOrganization(ctx context.Context, idOrSlug string) (*Organization, error) {
+	var response Organization
+	if err := c.do(ctx, http.MethodPatch, "/organizations/"+url.PathEscape(idOrSlug)+"/set-active", nil, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ListOrganizationMembers returns the members of an organization, including archived ones.
+// It requires a session cookie.
+func (c *Client) ListOrganizationMembers(ctx context.Context, idOrSlug string) ([]Member, error) {
+	var response OrganizationMembersResponse
+	if err := c.do(ctx, http.MethodGet, "/organizations/"+url.PathEscape(idOrSlug)+"/members", nil, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Members, nil
+}
+
+// OrganizationSubscription returns the billing plan of an organization, or nil if it has none.
+// It requires a session cookie.
+func (c *Client) OrganizationSubscription(ctx context.Context, idOrSlug string) (*Subscription, error) {
+	var response *Subscription
+	if err := c.do(ctx, http.MethodGet, "/organizations/"+url.PathEscape(idOrSlug)+"/stripe/subscription", nil, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// IssueToken exchanges the session cookie for a new JWT token, which carries
+// the user's current active organization
+func (c *Client) IssueToken(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/auth/token", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setHeaders(req, c.creds.current())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return "", &APIError{Status: resp.StatusCode, Body: string(respBody)}
+	}
+
+	if token := resp.Header.Get("set-auth-jwt"); token != "" {
+		return token, nil
+	}
+
+	var response struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(respBody, &response); err != nil || response.Token == "" {
+		return "", fmt.Errorf("no token in response")
+	}
+
+	return response.Token, nil
+}
+
+// CreateToken creates a long-lived token for automation. It requires a session cookie.
+func (c *Client) CreateToken(ctx context.Context, req *CreateTokenRequest) (*CreatedToken, error) {
+	var response CreatedToken
+	if err := c.do(ctx, http.MethodPost, "/cli/tokens", nil, req, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ListTokens lists the long-lived tokens that haven't expired or been revoked.
+// It requires a session cookie.
+func (c *Client) ListTokens(ctx context.Context) ([]Token, error) {
+	var response struct {
+		Tokens []Token `json:"tokens"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/cli/tokens", nil, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Tokens, nil
+}
+
+// RevokeToken revokes a long-lived token. It requires a session cookie.
+func (c *Client) RevokeToken(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/cli/tokens/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// ExchangeAuthCode exchanges the authorization code of a browser login for a
+// session cookie, proving with the PKCE verifier that this client started it
+func (c *Client) ExchangeAuthCode(ctx context.Context, code, codeVerifier, redirectURI string) (string, error) {
+	req := &ExchangeAuthCodeRequest{Code: code, CodeVerifier: codeVerifier, RedirectURI: redirectURI}
+	var response SessionCookieResponse
+	if err := c.do(ctx, http.MethodPost, "/cli/auth/token", nil, req, &response); err != nil {
+		return "", err
+	}
+
+	return response.SessionCookie, nil
+}
+
+// StartDeviceAuthorization starts a device code login
+func (c *Client) StartDeviceAuthorization(ctx context.Context) (*DeviceAuthorization, error) {
+	var response DeviceAuthorization
+	if err := c.do(ctx, http.MethodPost, "/cli/auth/device", nil, struct{}{}, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// PollDeviceAuthorization returns the state of a device code login
+func (c *Client) PollDeviceAuthorization(ctx context.Context, deviceCode string) (*DeviceAuthorizationStatus, error) {
+	req := &PollDeviceAuthorizationRequest{DeviceCode: deviceCode}
+	var response DeviceAuthorizationStatus
+	if err := c.do(ctx, http.MethodPost, "/cli/auth/device/token", nil, req, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// JWKS returns the JSON Web Key Set the server signs its tokens with
+func (c *Client) JWKS(ctx context.Context) (json.RawMessage, error) {
+	var response json.RawMessage
+	if err := c.do(ctx, http.MethodGet, "/auth/jwks", nil, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// setHeaders adds the credentials and client headers to a request
+func (c *Client) setHeaders(req *http.Request, token string) {
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if c.sessionCookie != "" {
+		req.Header.Set("Cookie", c.sessionCookie)
+	}
+	if c.organization != "" {
+		req.Header.Set(OrganizationHeader, c.organization)
+	}
+	if c.timezone != "" {
+		req.Header.Set(TimezoneHeader, c.timezone)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+}
+
+// do sends an authenticated request and decodes the JSON response into out.
+// If the token is rejected and a refresher is set, the request is retried once.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var jsonData []byte
+	if body != nil {
+		var err error
+		jsonData, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to prepare request: %w", err)
+		}
+	}
+
+	token := c.creds.current()
+	respBody, err := c.send(ctx, method, endpoint, jsonData, token)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusUnauthorized && c.creds.refresh != nil {
+		if newToken, refreshErr := c.creds.refreshAfter(ctx, token); refreshErr == nil {
+			respBody, err = c.send(ctx, method, endpoint, jsonData, newToken)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return nil
+}
+
+// send performs a single request and returns the response body, or an
+// *APIError for error statuses
+func (c *Client) send(ctx context.Context, method, endpoint string, jsonData []byte, token string) ([]byte, error) {
+	var reqBody io.Reader
+	if jsonData != nil {
+		reqBody = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.setHeaders(req, token)
+	if jsonData != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, &APIError{Status: resp.StatusCode, Body: string(respBody)}
+	}
+
+	return respBody, nil
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrUnauthorized is matched by API errors with status 401
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrNotFound is matched by API errors with status 404
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is matched by API errors with status 409
+	ErrConflict = errors.New("conflict")
+)
+
+// APIError is returned for any response with a status code of 400 or above.
+// Use errors.Is with ErrUnauthorized, ErrNotFound or ErrConflict to check for
+// common cases.
+type APIError struct {
+	Status int
+	Body   string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("server error (status %d): %s", e.Status, e.Body)
+}
+
+// Is reports whether the error matches one of the package sentinel errors
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.Status == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.Status == http.StatusNotFound
+	case ErrConflict:
+		return e.Status == http.StatusConflict
+	}
+	return false
+}
+
+// Message returns the human readable message from the error body if the
+// server sent one, falling back to the raw body
+func (e *APIError) Message() string {
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(e.Body), &body); err == nil && body.Message != "" {
+		return body.Message
+	}
+	return e.Body
+}
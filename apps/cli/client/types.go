@@ -0,0 +1,283 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ItemType identifies the kind of a status update item
+type ItemType string
+
+const (
+	// ItemTypeDone marks a completed task
+	ItemTypeDone ItemType = "done"
+	// ItemTypeProgress marks work in progress
+	ItemTypeProgress ItemType = "progress"
+	// ItemTypeBlocker marks a blocked task
+	ItemTypeBlocker ItemType = "blocker"
+)
+
+// StatusUpdate represents a status update with items
+type StatusUpdate struct {
+	ID             string             `json:"id"`
+	MemberID       string             `json:"memberId"`
+	OrganizationID string             `json:"organizationId"`
+	TeamID         *string            `json:"teamId"`
+	EffectiveFrom  time.Time          `json:"effectiveFrom"`
+	EffectiveTo    time.Time          `json:"effectiveTo"`
+	Mood           *string            `json:"mood"`
+	Emoji          *string            `json:"emoji"`
+	Notes          *string            `json:"notes"`
+	IsDraft        bool               `json:"isDraft"`
+	Timezone       string             `json:"timezone"`
+	CreatedAt      time.Time          `json:"createdAt"`
+	UpdatedAt      time.Time          `json:"updatedAt"`
+	Items          []StatusUpdateItem `json:"items"`
+	Member         Member             `json:"member"`
+	Team           *Team              `json:"team"`
+}
+
+// StatusUpdateItem represents a single item in a status update
+type StatusUpdateItem struct {
+	ID             string    `json:"id"`
+	StatusUpdateID string    `json:"statusUpdateId"`
+	Content        string    `json:"content"`
+	IsBlocker      bool      `json:"isBlocker"`
+	IsInProgress   bool      `json:"isInProgress"`
+	Order          int       `json:"order"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+	// IdempotencyKey is the key the item was added with, if any
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+}
+
+// Type returns the item type derived from the blocker and in-progress flags
+func (i StatusUpdateItem) Type() ItemType {
+	if i.IsBlocker {
+		return ItemTypeBlocker
+	}
+	if i.IsInProgress {
+		return ItemTypeProgress
+	}
+	return ItemTypeDone
+}
+
+// Member represents a member with user information
+type Member struct {
+	ID             string     `json:"id"`
+	OrganizationID string     `json:"organizationId"`
+	UserID         string     `json:"userId"`
+	Role           string     `json:"role,omitempty"`
+	ArchivedAt     *time.Time `json:"archivedAt,omitempty"`
+	User           User       `json:"user"`
+}
+
+// User represents user information
+type User struct {
+	ID       string  `json:"id"`
+	Email    string  `json:"email"`
+	Name     string  `json:"name"`
+	Timezone *string `json:"timezone"`
+}
+
+// Team represents team information
+type Team struct {
+	ID             string `json:"id"`
+	OrganizationID string `json:"organizationId"`
+	Name           string `json:"name"`
+	Slug           string `json:"slug"`
+}
+
+// Organization represents an organization the user belongs to
+type Organization struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Slug        string     `json:"slug"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	TrialPlan   *string    `json:"trialPlan"`
+	TrialStatus *string    `json:"trialStatus"`
+	TrialEnd    *time.Time `json:"trialEndDate"`
+}
+
+// OrganizationMembership pairs an organization with the user's membership in it
+type OrganizationMembership struct {
+	Organization Organization `json:"organization"`
+	Member       Member       `json:"member"`
+}
+
+// Whoami represents the logged in user with their membership in the active organization
+type Whoami struct {
+	User         User         `json:"user"`
+	Organization Organization `json:"organization"`
+	Member       Member       `json:"member"`
+	Teams        []Team       `json:"teams"`
+}
+
+// OrganizationMembersResponse represents the API response for listing organization members
+type OrganizationMembersResponse struct {
+	Members []Member `json:"members"`
+}
+
+// Subscription represents the billing plan of an organization
+type Subscription struct {
+	Status     string  `json:"status"`
+	PlanName   *string `json:"planName"`
+	PlanSource *string `json:"planSource"`
+}
+
+// StatusUpdateResponse represents the API response for retrieving a status update
+type StatusUpdateResponse struct {
+	StatusUpdate *StatusUpdate `json:"statusUpdate"`
+	Message      string        `json:"message"`
+}
+
+// ListStatusUpdatesResponse represents the API response for listing status updates
+type ListStatusUpdatesResponse struct {
+	StatusUpdates []StatusUpdate `json:"statusUpdates"`
+	Message       string         `json:"message"`
+}
+
+// UndoResponse represents the API response for removing a status update item
+type UndoResponse struct {
+	Success             bool   `json:"success"`
+	DeletedStatusUpdate bool   `json:"deletedStatusUpdate"`
+	Message             string `json:"message"`
+}
+
+// AddStatusUpdateItemRequest represents the API request for adding a status update item
+type AddStatusUpdateItemRequest struct {
+	Type           ItemType `json:"type"`
+	Message        string   `json:"message"`
+	IdempotencyKey string   `json:"idempotencyKey,omitempty"`
+}
+
+// EditStatusUpdateRequest represents the API request for editing a status update
+type EditStatusUpdateRequest struct {
+	Items []EditStatusUpdateItem `json:"items"`
+	Date  string                 `json:"date,omitempty"`
+	Mood  *string                `json:"mood"`
+	Notes *string                `json:"notes"`
+}
+
+// EditStatusUpdateItem represents a single item in the edit request
+type EditStatusUpdateItem struct {
+	Content string `json:"content"`
+	Type    string `json:"type"`
+	Order   int    `json:"order"`
+}
+
+// ItemChangeOp is the kind of change made to a status update item
+type ItemChangeOp string
+
+const (
+	// ItemChangeInsert adds a new item
+	ItemChangeInsert ItemChangeOp = "insert"
+	// ItemChangeUpdate changes the content, type or order of an existing item
+	ItemChangeUpdate ItemChangeOp = "update"
+	// ItemChangeDelete removes an existing item
+	ItemChangeDelete ItemChangeOp = "delete"
+)
+
+// ItemChange is a change to one status update item. Updates only carry the
+// fields that changed; inserts carry all of them.
+type ItemChange struct {
+	Op      ItemChangeOp `json:"op"`
+	ID      string       `json:"id,omitempty"`
+	Content string       `json:"content,omitempty"`
+	Type    ItemType     `json:"type,omitempty"`
+	Order   int          `json:"order,omitempty"`
+	// IdempotencyKey makes the server skip an insert whose key the status
+	// update already has, so a retried insert adds the item once
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+}
+
+// PatchStatusUpdateRequest represents the API request for changing the items
+// of a status update by ID, keeping the identity of unchanged items
+type PatchStatusUpdateRequest struct {
+	Changes []ItemChange `json:"changes"`
+	Date    string       `json:"date,omitempty"`
+	// Mood and Notes are left out when nil, so the server keeps them
+	Mood  *NullableString `json:"mood,omitempty"`
+	Notes *NullableString `json:"notes,omitempty"`
+	// ExpectedUpdatedAt makes the request fail with ErrConflict when the
+	// status update changed since it was fetched
+	ExpectedUpdatedAt *Precondition `json:"expectedUpdatedAt,omitempty"`
+}
+
+// Precondition is the updatedAt a status update must still have for a change
+// to apply. A nil UpdatedAt requires that no status update exists yet.
+type Precondition struct {
+	UpdatedAt *time.Time
+}
+
+// MarshalJSON encodes the precondition as its timestamp, or null
+func (p Precondition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.UpdatedAt)
+}
+
+// NullableString is a field of a request that is set to Value, or cleared
+// with null when Value is nil
+type NullableString struct {
+	Value *string
+}
+
+// MarshalJSON encodes the field as its value, or null
+func (n NullableString) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.Value)
+}
+
+// CreateTokenRequest represents the API request for creating a long-lived token
+type CreateTokenRequest struct {
+	Name          string `json:"name"`
+	ExpiresInDays int    `json:"expiresInDays"`
+}
+
+// CreatedToken represents a long-lived token created for automation
+type CreatedToken struct {
+	ID        string    `json:"id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Token represents a long-lived token that can still be used
+type Token struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ExchangeAuthCodeRequest represents the API request for exchanging a browser
+// login authorization code
+type ExchangeAuthCodeRequest struct {
+	Code         string `json:"code"`
+	CodeVerifier string `json:"codeVerifier"`
+	RedirectURI  string `json:"redirectUri"`
+}
+
+// SessionCookieResponse represents a web session handed to the CLI
+type SessionCookieResponse struct {
+	SessionCookie string `json:"sessionCookie"`
+}
+
+// DeviceAuthorization represents a pending device code login
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"deviceCode"`
+	UserCode                string `json:"userCode"`
+	VerificationURI         string `json:"verificationUri"`
+	VerificationURIComplete string `json:"verificationUriComplete"`
+	ExpiresIn               int    `json:"expiresIn"`
+	Interval                int    `json:"interval"`
+}
+
+// PollDeviceAuthorizationRequest represents the API request for the state of a device code login
+type PollDeviceAuthorizationRequest struct {
+	DeviceCode string `json:"deviceCode"`
+}
+
+// DeviceAuthorizationStatus represents the state of a device code login.
+// SessionCookie is set once the login is approved.
+type DeviceAuthorizationStatus struct {
+	Status        string `json:"status"`
+	SessionCookie string `json:"sessionCookie,omitempty"`
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// cachePolicy controls whether show and list read from the local cache or the API
+type cachePolicy int
+
+const (
+	// cachePolicyDefault serves completed past days from the cache and
+	// revalidates anything that may still change
+	cachePolicyDefault cachePolicy = iota
+	// cachePolicyCached serves any cached copy without contacting the API
+	cachePolicyCached
+	// cachePolicyFresh always fetches from the API
+	cachePolicyFresh
+)
+
+var (
+	useCachedFlag bool
+	useFreshFlag  bool
+)
+
+// addCacheFlags registers the --cached and --fresh flags on a command
+func addCacheFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&useCachedFlag, "cached", false, "Serve from the local cache without contacting the server when possible")
+	cmd.Flags().BoolVar(&useFreshFlag, "fresh", false, "Always fetch from the server and refresh the local cache")
+}
+
+// getCachePolicy returns the cache policy selected by the command line flags
+func getCachePolicy() (cachePolicy, error) {
+	switch {
+	case useCachedFlag && useFreshFlag:
+		return cachePolicyDefault, validationError("--cached and --fresh cannot be used together")
+	case useCachedFlag:
+		return cachePolicyCached, nil
+	case useFreshFlag:
+		return cachePolicyFresh, nil
+	}
+	return cachePolicyDefault, nil
+}
+
+// cachedStatusUpdate is a status update stored in the local cache.
+// A nil StatusUpdate records that the server had no update for the date.
+// Timezone is the zone the date was looked up in; entries from before the
+// CLI sent a zone have none and were looked up by UTC day.
+type cachedStatusUpdate struct {
+	Date         string        `json:"date"`
+	Timezone     string        `json:"timezone,omitempty"`
+	FetchedAt    time.Time     `json:"fetchedAt"`
+	StatusUpdate *StatusUpdate `json:"statusUpdate"`
+}
+
+// isComplete reports whether the entry was fetched after its day ended,
+// so it won't change through this CLI any more
+func (c *cachedStatusUpdate) isComplete() bool {
+	location := time.UTC
+	if zone, err := loadTimezone(c.Timezone); err == nil {
+		location = zone.location
+	}
+	day, err := time.ParseInLocation("2006-01-02", c.Date, location)
+	if err != nil {
+		return false
+	}
+	return c.FetchedAt.After(day.AddDate(0, 0, 1))
+}
+
+// getStatusUpdateCacheDir returns the directory holding cached status updates.
+// Status updates of an explicitly selected organization are cached separately.
+func getStatusUpdateCacheDir() string {
+	if org := getActiveOrganization(); org != "" {
+		return filepath.Join(getActiveProfileCacheDir(), "cache", "orgs", org, "status-updates")
+	}
+	return filepath.Join(getActiveProfileCacheDir(), "cache", "status-updates")
+}
+
+// statusUpdateCache is the cache of the active profile and organization in
+// the active zone. It's resolved once, so goroutines can share it without
+// loading the config.
+type statusUpdateCache struct {
+	dir      string
+	timezone string
+}
+
+// openStatusUpdateCache resolves the cache of the active profile and organization
+func openStatusUpdateCache() statusUpdateCache {
+	return statusUpdateCache{dir: getStatusUpdateCacheDir(), timezone: getTimezone().name}
+}
+
+// read returns the cached status update for a YYYY-MM-DD date
+func (c statusUpdateCache) read(date string) (*cachedStatusUpdate, bool) {
+	content, err := os.ReadFile(filepath.Join(c.dir, date+".json"))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cachedStatusUpdate
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return nil, false
+	}
+	// A day in another zone covers other hours, so it can't be reused
+	if entry.Timezone != c.timezone {
+		return nil, false
+	}
+
+	return &entry, true
+}
+
+// write stores the status update for a YYYY-MM-DD date.
+// The cache is best effort, so failures are ignored.
+func (c statusUpdateCache) write(date string, statusUpdate *StatusUpdate) {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return
+	}
+
+	jsonData, err := json.Marshal(cachedStatusUpdate{
+		Date:         date,
+		Timezone:     c.timezone,
+		FetchedAt:    time.Now(),
+		StatusUpdate: statusUpdate,
+	})
+	if err != nil {
+		return
+	}
+
+	_ = writeFileAtomic(filepath.Join(c.dir, date+".json"), jsonData, 0600)
+}
+
+// readStatusUpdateCache returns the cached status update for a YYYY-MM-DD date
+func readStatusUpdateCache(date string) (*cachedStatusUpdate, bool) {
+	return openStatusUpdateCache().read(date)
+}
+
+// writeStatusUpdateCache stores the status update for a YYYY-MM-DD date
+func writeStatusUpdateCache(date string, statusUpdate *StatusUpdate) {
+	openStatusUpdateCache().write(date, statusUpdate)
+}
+
+// invalidateStatusUpdateCache removes the cached status update for a YYYY-MM-DD date
+func invalidateStatusUpdateCache(date string) {
+	_ = os.Remove(filepath.Join(getStatusUpdateCacheDir(), date+".json"))
+}
+
+// statusUpdateCacheKey returns the cache date key of a status update returned by the API
+func statusUpdateCacheKey(statusUpdate *StatusUpdate) string {
+	return statusUpdateDate(statusUpdate)
+}
+
+// sameStatusUpdateVersion reports whether two copies of a status update are the same revision
+func sameStatusUpdateVersion(a, b *StatusUpdate) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.ID == b.ID && a.UpdatedAt.Equal(b.UpdatedAt)
+}
+
+// revalidationResult is the outcome of refreshing a cached status update from the API
+type revalidationResult struct {
+	statusUpdate *StatusUpdate
+	err          error
+}
+
+// getStatusUpdateWithCache returns the status update for a YYYY-MM-DD date according to the cache policy.
+// When a cached copy is returned that may be outdated, a refresh is started in the background and
+// its result is delivered on the returned channel; the cache is updated once it completes.
+func getStatusUpdateWithCache(ctx context.Context, date string, policy cachePolicy) (*StatusUpdate, <-chan revalidationResult, error) {
+	cache := openStatusUpdateCache()
+	cached, ok := cache.read(date)
+
+	if ok && policy == cachePolicyCached {
+		return cached.StatusUpdate, nil, nil
+	}
+	if ok && policy == cachePolicyDefault && cached.isComplete() {
+		return cached.StatusUpdate, nil, nil
+	}
+
+	if ok && policy == cachePolicyDefault {
+		revalidation := make(chan revalidationResult, 1)
+		go func() {
+			statusUpdate, err := getStatusUpdateByDate(ctx, date)
+			if err == nil {
+				cache.write(date, statusUpdate)
+			}
+			revalidation <- revalidationResult{statusUpdate: statusUpdate, err: err}
+		}()
+		return cached.StatusUpdate, revalidation, nil
+	}
+
+	statusUpdate, err := getStatusUpdateByDate(ctx, date)
+	if err != nil {
+		return nil, nil, err
+	}
+	cache.write(date, statusUpdate)
+
+	return statusUpdate, nil, nil
+}
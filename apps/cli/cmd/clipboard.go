@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardTool is a program that reads the clipboard contents from stdin
+type clipboardTool struct {
+	name string
+	args []string
+	// htmlArgs replace args when the contents are HTML, so rich text editors
+	// paste them formatted; tools without them copy HTML as plain text
+	htmlArgs []string
+}
+
+// clipboardTools returns the clipboard programs to try on this system, in order
+func clipboardTools() []clipboardTool {
+	switch runtime.GOOS {
+	case "darwin":
+		return []clipboardTool{{name: "pbcopy"}}
+	case "windows":
+		return []clipboardTool{{name: "clip.exe"}}
+	}
+
+	var tools []clipboardTool
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		tools = append(tools, clipboardTool{name: "wl-copy", htmlArgs: []string{"--type", "text/html"}})
+	}
+	return append(tools,
+		clipboardTool{name: "xclip", args: []string{"-selection", "clipboard"}, htmlArgs: []string{"-selection", "clipboard", "-t", "text/html"}},
+		clipboardTool{name: "xsel", args: []string{"--clipboard", "--input"}},
+	)
+}
+
+// copyToClipboard puts text on the system clipboard with the first available
+// clipboard program and returns its name
+func copyToClipboard(text string, isHTML bool) (string, error) {
+	tools := clipboardTools()
+	for _, tool := range tools {
+		path, err := exec.LookPath(tool.name)
+		if err != nil {
+			continue
+		}
+
+		args := tool.args
+		if isHTML && tool.htmlArgs != nil {
+			args = tool.htmlArgs
+		}
+		// xclip and wl-copy fork a child that keeps the selection and inherits
+		// stderr, so it isn't captured: Run would wait on that pipe until
+		// something else takes over the clipboard
+		cmd := exec.Command(path, args...)
+		cmd.Stdin = strings.NewReader(text)
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				return "", fmt.Errorf("%s failed with exit status %d", tool.name, exitErr.ExitCode())
+			}
+			return "", fmt.Errorf("%s failed: %v", tool.name, err)
+		}
+		return tool.name, nil
+	}
+
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.name)
+	}
+	return "", &cliError{
+		class:   errorClassValidation,
+		message: fmt.Sprintf("no clipboard tool found, looked for %s", strings.Join(names, ", ")),
+		hint:    "install wl-clipboard (Wayland), xclip or xsel, or drop --copy and pipe the output yourself",
+	}
+}
@@ -1,156 +1,491 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"os"
-	"time"
+	"path/filepath"
+	"slices"
+	"sort"
 
-	"github.com/fatih/color"
+	"asyncstatus.com/cli/client"
 )
 
-// Config represents the stored configuration
+// defaultProfileName is the profile used when none is selected
+const defaultProfileName = "default"
+
+// profileFlag holds the value of the global --profile flag
+var profileFlag string
+
+// orgFlag holds the value of the global --org flag
+var orgFlag string
+
+// configVersion is the version of the config file format written by this CLI.
+// Version 1 had no settings and no version field.
+const configVersion = 2
+
+// Config represents the stored configuration. Settings are the global
+// settings, overridden by those of the active profile.
 type Config struct {
+	Version         int                 `json:"version"`
+	CurrentProfile  string              `json:"currentProfile"`
+	CredentialStore string              `json:"credentialStore,omitempty"`
+	Settings        map[string]string   `json:"settings,omitempty"`
+	Profiles        map[string]*Profile `json:"profiles"`
+}
+
+// Profile bundles the server, credentials and organization used by commands,
+// like a kubectl context. Token and SessionCookie are only set with the
+// plaintext credential store; use loadCredentials to read them. Settings
+// override the global settings while the profile is active.
+type Profile struct {
+	APIURL        string            `json:"apiUrl"`
+	Email         string            `json:"email,omitempty"`
+	Token         string            `json:"token,omitempty"`
+	SessionCookie string            `json:"sessionCookie,omitempty"`
+	Organization  string            `json:"organization,omitempty"`
+	Settings      map[string]string `json:"settings,omitempty"`
+}
+
+// legacyConfig is the config file format before profiles were introduced
+type legacyConfig struct {
 	Email string `json:"email"`
 	Token string `json:"token"`
 }
 
 // getConfigPath returns the path to the config file
 func getConfigPath() string {
-	return os.ExpandEnv("$HOME/.asyncstatus/config.json")
+	return filepath.Join(getConfigDir(), "config.json")
 }
 
-// getConfigDir returns the path to the config directory
+// getConfigDir returns the path to the config directory:
+// $XDG_CONFIG_HOME/asyncstatus when XDG_CONFIG_HOME is set, otherwise ~/.asyncstatus
 func getConfigDir() string {
+	if xdgConfigHome := os.Getenv("XDG_CONFIG_HOME"); xdgConfigHome != "" {
+		return filepath.Join(xdgConfigHome, "asyncstatus")
+	}
+	return getLegacyConfigDir()
+}
+
+// getLegacyConfigDir returns the config directory used before XDG_CONFIG_HOME
+// was respected. The installer keeps the binary there in any case.
+func getLegacyConfigDir() string {
 	return os.ExpandEnv("$HOME/.asyncstatus")
 }
 
+// getProfileDir returns the directory holding local data (outbox, and cache and
+// drafts unless XDG_CACHE_HOME or XDG_STATE_HOME is set) of a profile
+func getProfileDir(name string) string {
+	return filepath.Join(getConfigDir(), "profiles", name)
+}
+
+// getProfileCacheDir returns the directory holding the cached data of a
+// profile: under $XDG_CACHE_HOME/asyncstatus when XDG_CACHE_HOME is set,
+// otherwise the profile directory
+func getProfileCacheDir(name string) string {
+	if xdgCacheHome := os.Getenv("XDG_CACHE_HOME"); xdgCacheHome != "" {
+		return filepath.Join(xdgCacheHome, "asyncstatus", "profiles", name)
+	}
+	return getProfileDir(name)
+}
+
+// getProfileStateDir returns the directory holding the drafts of a profile:
+// under $XDG_STATE_HOME/asyncstatus when XDG_STATE_HOME is set, otherwise the
+// profile directory
+func getProfileStateDir(name string) string {
+	if xdgStateHome := os.Getenv("XDG_STATE_HOME"); xdgStateHome != "" {
+		return filepath.Join(xdgStateHome, "asyncstatus", "profiles", name)
+	}
+	return getProfileDir(name)
+}
+
+// getProfileDataDirs returns the distinct directories holding local data of a
+// profile
+func getProfileDataDirs(name string) []string {
+	dirs := []string{getProfileDir(name)}
+	for _, dir := range []string{getProfileCacheDir(name), getProfileStateDir(name)} {
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// envTokenProfileDir holds the local data while ASYNCSTATUS_TOKEN is set. The
+// token may belong to another account than the profile, so their cache and
+// outbox are kept apart. Profile names can't start with "_".
+const envTokenProfileDir = "_env"
+
+// getActiveProfileDir returns the local data directory of the active profile
+func getActiveProfileDir() string {
+	return getProfileDir(activeProfileDirName())
+}
+
+// getActiveProfileCacheDir returns the cache directory of the active profile
+func getActiveProfileCacheDir() string {
+	return getProfileCacheDir(activeProfileDirName())
+}
+
+// getActiveProfileStateDir returns the drafts directory of the active profile
+func getActiveProfileStateDir() string {
+	return getProfileStateDir(activeProfileDirName())
+}
+
+// activeProfileDirName returns the name of the local data directories of the
+// active profile
+func activeProfileDirName() string {
+	if tokenFromEnv() != "" {
+		return envTokenProfileDir
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return defaultProfileName
+	}
+	return config.activeProfileName()
+}
+
+// activeProfileName returns the profile selected by --profile, ASYNCSTATUS_PROFILE
+// or `asyncstatus context use`, in that order
+func (c *Config) activeProfileName() string {
+	if profileFlag != "" {
+		return profileFlag
+	}
+	if name := os.Getenv("ASYNCSTATUS_PROFILE"); name != "" {
+		return name
+	}
+	if c.CurrentProfile != "" {
+		return c.CurrentProfile
+	}
+	return defaultProfileName
+}
+
+// activeProfile returns the active profile, or nil if it doesn't exist yet
+func (c *Config) activeProfile() *Profile {
+	return c.Profiles[c.activeProfileName()]
+}
+
+// profileNames returns the names of all profiles in sorted order
+func (c *Config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // isLoggedIn checks if the user is currently logged in
 func isLoggedIn() bool {
-	_, err := os.Stat(getConfigPath())
+	_, err := loadCredentials()
 	return err == nil
 }
 
-// loadConfig loads the current configuration
+// loadConfig loads the current configuration, migrating older formats if
+// needed. A missing config file yields an empty configuration.
 func loadConfig() (*Config, error) {
+	if err := migrateConfigDir(); err != nil {
+		return nil, err
+	}
+
 	content, err := os.ReadFile(getConfigPath())
+	if os.IsNotExist(err) {
+		return &Config{Version: configVersion, Profiles: map[string]*Profile{}}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
-	
+
 	var config Config
 	if err := json.Unmarshal(content, &config); err != nil {
 		return nil, fmt.Errorf("invalid config file format: %v", err)
 	}
-	
+
+	if config.Profiles == nil {
+		return migrateLegacyConfig(content)
+	}
+	if config.Version > configVersion {
+		return nil, &cliError{
+			class:   errorClassValidation,
+			message: fmt.Sprintf("%s was written by a newer version of the CLI (format %d)", getConfigPath(), config.Version),
+			hint:    "run: asyncstatus upgrade",
+		}
+	}
+	if config.Version < configVersion {
+		// Version 1 files only lack the version field and settings
+		if err := saveConfig(&config); err != nil {
+			return nil, err
+		}
+	}
+	if err := migrateProfileData(&config); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
+// migrateConfigDir moves the config file and profile data from ~/.asyncstatus
+// to $XDG_CONFIG_HOME/asyncstatus the first time XDG_CONFIG_HOME is set.
+// The installed binary is left where it is.
+func migrateConfigDir() error {
+	configDir, legacyDir := getConfigDir(), getLegacyConfigDir()
+	if configDir == legacyDir {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(configDir, "config.json")); !os.IsNotExist(err) {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(legacyDir, "config.json")); err != nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+	// config.json goes last, so an interrupted move is resumed next time
+	for _, name := range []string{"profiles", "outbox.json", "cache", "templates", "config.json"} {
+		legacyPath := filepath.Join(legacyDir, name)
+		if _, err := os.Stat(legacyPath); err != nil {
+			continue
+		}
+		if err := os.Rename(legacyPath, filepath.Join(configDir, name)); err != nil {
+			return fmt.Errorf("failed to move %s to %s: %v", legacyPath, configDir, err)
+		}
+	}
+	return nil
+}
+
+// migrateProfileData moves the cache and drafts of each profile out of the
+// profile directory the first time XDG_CACHE_HOME or XDG_STATE_HOME is set
+func migrateProfileData(config *Config) error {
+	names := append(config.profileNames(), envTokenProfileDir)
+	for _, name := range names {
+		moves := map[string]string{
+			"cache":     getProfileCacheDir(name),
+			"jwks.json": getProfileCacheDir(name),
+			"drafts":    getProfileStateDir(name),
+		}
+		for entry, dir := range moves {
+			if dir == getProfileDir(name) {
+				continue
+			}
+			oldPath, newPath := filepath.Join(getProfileDir(name), entry), filepath.Join(dir, entry)
+			if _, err := os.Stat(oldPath); err != nil {
+				continue
+			}
+			if _, err := os.Stat(newPath); !os.IsNotExist(err) {
+				continue
+			}
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				return fmt.Errorf("failed to create %s: %v", dir, err)
+			}
+			if err := os.Rename(oldPath, newPath); err != nil {
+				return fmt.Errorf("failed to move %s to %s: %v", oldPath, dir, err)
+			}
+		}
+	}
+	return nil
+}
+
+// migrateLegacyConfig converts a config file holding a single email and token
+// into a "default" profile and moves the local data into the profile directory
+func migrateLegacyConfig(content []byte) (*Config, error) {
+	var legacy legacyConfig
+	if err := json.Unmarshal(content, &legacy); err != nil {
+		return nil, fmt.Errorf("invalid config file format: %v", err)
+	}
+
+	apiURL := os.Getenv("ASYNCSTATUS_API_URL")
+	if apiURL == "" {
+		apiURL = client.DefaultBaseURL
+	}
+
+	config := &Config{
+		Version:        configVersion,
+		CurrentProfile: defaultProfileName,
+		Profiles: map[string]*Profile{
+			defaultProfileName: {
+				APIURL: apiURL,
+				Email:  legacy.Email,
+				Token:  legacy.Token,
+			},
+		},
+	}
+
+	profileDir := getProfileDir(defaultProfileName)
+	if err := os.MkdirAll(profileDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create profile directory: %v", err)
+	}
+	for _, name := range []string{"outbox.json", "cache"} {
+		legacyPath := filepath.Join(getConfigDir(), name)
+		if _, err := os.Stat(legacyPath); err == nil {
+			if err := os.Rename(legacyPath, filepath.Join(profileDir, name)); err != nil {
+				return nil, fmt.Errorf("failed to migrate %s: %v", name, err)
+			}
+		}
+	}
+
+	if err := saveConfig(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
 // saveConfig saves the configuration to disk
 func saveConfig(config *Config) error {
 	// Create config directory with restricted permissions (700 = rwx------)
 	if err := os.MkdirAll(getConfigDir(), 0700); err != nil {
 		return fmt.Errorf("failed to create config directory: %v", err)
 	}
-	
+
+	// Changed settings take effect for the rest of the run
+	resolvedSettings = nil
+	config.Version = configVersion
+
 	// Convert to JSON
-	jsonData, err := json.Marshal(config)
+	jsonData, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %v", err)
 	}
-	
+
 	// Write file with restricted permissions (600 = rw-------)
 	// This ensures only the current user can read/write the file
-	if err := os.WriteFile(getConfigPath(), jsonData, 0600); err != nil {
+	if err := writeFileAtomic(getConfigPath(), jsonData, 0600); err != nil {
 		return fmt.Errorf("failed to save config: %v", err)
 	}
-	
+
 	return nil
 }
 
-// clearConfig removes the configuration file
-func clearConfig() error {
-	if err := os.Remove(getConfigPath()); err != nil && !os.IsNotExist(err) {
+// clearProfileCredentials removes the stored credentials of the active profile,
+// keeping its server URL so the next login goes to the same place
+func clearProfileCredentials() error {
+	config, err := loadConfig()
+	if err != nil {
 		return fmt.Errorf("failed to clear config: %v", err)
 	}
-	return nil
+
+	if profile := config.activeProfile(); profile != nil {
+		if err := eraseCredentials(config, config.activeProfileName()); err != nil {
+			return err
+		}
+		profile.Email = ""
+	}
+
+	return saveConfig(config)
 }
 
-// getCurrentUserEmail retrieves the currently logged in user's email
-// If no user is found, it shows an authentication error and exits
-func getCurrentUserEmail() string {
+// getAPIURL returns the API base URL: ASYNCSTATUS_API_URL if set, otherwise
+// the URL of the active profile, otherwise the api-url setting or production
+func getAPIURL() string {
+	return getSetting("api-url")
+}
+
+// loadActiveProfile returns the active profile and its name, or an
+// authentication error if it doesn't exist
+func loadActiveProfile() (*Profile, string, error) {
 	config, err := loadConfig()
 	if err != nil {
-		color.New(color.FgRed).Println("⧗ not authenticated")
-		color.New(color.FgHiBlack).Println("  run:", color.New(color.FgWhite).Sprint("asyncstatus login"), "first")
-		os.Exit(1)
+		return nil, "", authError("not authenticated: %v", err)
 	}
-	
-	if config.Email == "" {
-		color.New(color.FgRed).Println("⧗ no user email found")
-		color.New(color.FgHiBlack).Println("  run:", color.New(color.FgWhite).Sprint("asyncstatus login"), "first")
-		os.Exit(1)
+
+	name := config.activeProfileName()
+	profile := config.activeProfile()
+	if profile == nil {
+		if name == defaultProfileName {
+			return nil, name, authError("not authenticated")
+		}
+		return nil, name, authError("not authenticated for profile %q", name)
 	}
-	
-	return config.Email
+
+	return profile, name, nil
 }
 
-// getCurrentToken retrieves the currently stored auth token
-// If no token is found, it shows an authentication error and exits
-func getCurrentToken() string {
-	config, err := loadConfig()
+// getCurrentUserEmail retrieves the currently logged in user's email
+// If no user is found, it returns an authentication error
+func getCurrentUserEmail() (string, error) {
+	profile, _, err := loadActiveProfile()
 	if err != nil {
-		color.New(color.FgRed).Println("⧗ not authenticated")
-		color.New(color.FgHiBlack).Println("  run:", color.New(color.FgWhite).Sprint("asyncstatus login"), "first")
-		os.Exit(1)
+		return "", err
 	}
-	
-	if config.Token == "" {
-		color.New(color.FgRed).Println("⧗ no authentication token found")
-		color.New(color.FgHiBlack).Println("  run:", color.New(color.FgWhite).Sprint("asyncstatus login"), "first")
-		os.Exit(1)
+
+	if profile.Email == "" {
+		return "", authError("no user email found")
 	}
-	
-	return config.Token
+
+	return profile.Email, nil
 }
 
-// makeAuthenticatedRequest creates an HTTP client and request with JWT authentication
-func makeAuthenticatedRequest(method, endpoint string) (*http.Client, *http.Request, error) {
-	token := getCurrentToken()
-	apiURL := os.Getenv("ASYNCSTATUS_API_URL")
-	if apiURL == "" {
-		apiURL = "https://api.asyncstatus.com"
+// getCurrentToken retrieves the currently stored auth token
+// If no token is found, it returns an authentication error
+func getCurrentToken() (string, error) {
+	creds, err := loadCredentials()
+	if err != nil {
+		return "", err
 	}
-	
-	// Create HTTP client
-	client := &http.Client{
-		Timeout: 30 * time.Second,
+
+	return creds.Token, nil
+}
+
+// getActiveOrganization returns the organization slug selected by --org or the
+// active profile, or "" to use the organization active on the server
+func getActiveOrganization() string {
+	if orgFlag != "" {
+		return orgFlag
 	}
-	
-	// Create request
-	url := apiURL + endpoint
-	req, err := http.NewRequest(method, url, nil)
+
+	if config, err := loadConfig(); err == nil {
+		if profile := config.activeProfile(); profile != nil {
+			return profile.Organization
+		}
+	}
+
+	return ""
+}
+
+// newAPIClient creates an API client authenticated with the stored JWT token.
+// The token is refreshed first if needed, and again if the server rejects it.
+func newAPIClient(ctx context.Context) (*client.Client, error) {
+	token, err := getFreshToken(ctx)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create request: %v", err)
+		return nil, err
 	}
-	
-	// Add authentication headers
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("User-Agent", "AsyncStatus-CLI/"+Version)
-	
-	return client, req, nil
+
+	return client.New(
+		getAPIURL(),
+		token,
+		client.WithUserAgent("AsyncStatus-CLI/"+Version),
+		client.WithTimeout(getHTTPTimeout()),
+		client.WithOrganization(getActiveOrganization()),
+		client.WithTimezone(getTimezone().name),
+		client.WithTokenRefresher(refreshStoredToken),
+	), nil
 }
 
-// makeAuthenticatedJSONRequest creates an HTTP client and request with JWT authentication and JSON content type
-func makeAuthenticatedJSONRequest(method, endpoint string) (*http.Client, *http.Request, error) {
-	client, req, err := makeAuthenticatedRequest(method, endpoint)
+// newSessionAPIClient creates an API client that also sends the stored web
+// session cookie, for the endpoints that don't accept JWT tokens
+func newSessionAPIClient(ctx context.Context) (*client.Client, error) {
+	token, err := getFreshToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := loadCredentials()
 	if err != nil {
-		return nil, nil, err
-	}
-	
-	// Set JSON content type for requests with body
-	req.Header.Set("Content-Type", "application/json")
-	
-	return client, req, nil
-}
\ No newline at end of file
+		return nil, err
+	}
+	if creds.SessionCookie == "" {
+		return nil, authError("no session stored for this profile, log in with a password or --web to use this command")
+	}
+
+	return client.New(
+		getAPIURL(),
+		token,
+		client.WithUserAgent("AsyncStatus-CLI/"+Version),
+		client.WithTimeout(getHTTPTimeout()),
+		client.WithSessionCookie(creds.SessionCookie),
+		client.WithOrganization(getActiveOrganization()),
+	), nil
+}
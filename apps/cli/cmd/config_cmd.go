@@ -0,0 +1,361 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var configScope string
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Get and set settings",
+	Long: `Get and set settings such as the editor, date format and request timeout.
+
+Each setting is taken from the first of:
+  1. its global flag, e.g. --color or --http-timeout
+  2. its environment variable, e.g. ASYNCSTATUS_DATE_FORMAT
+  3. the settings of the active profile (--scope profile)
+  4. the global settings (--scope global, the default)
+  5. the built-in default
+
+A profile always keeps the API URL it logged in to, so a global api-url only
+picks the server of profiles logged in afterwards.
+
+Settings are kept in config.json under $XDG_CONFIG_HOME/asyncstatus when
+XDG_CONFIG_HOME is set, otherwise under ~/.asyncstatus. The cache and edit
+drafts go under $XDG_CACHE_HOME/asyncstatus and $XDG_STATE_HOME/asyncstatus
+when those are set.
+
+Examples:
+  asyncstatus config list
+  asyncstatus config get date-format
+  asyncstatus config set date-format iso
+  asyncstatus config set default-type progress --scope profile
+  asyncstatus config unset editor
+  asyncstatus config edit`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleConfigList()
+	},
+}
+
+// configListCmd represents the config list command
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List settings with their values and where they come from",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleConfigList()
+	},
+}
+
+// configGetCmd represents the config get command
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a setting",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleConfigGet(args[0])
+	},
+}
+
+// configSetCmd represents the config set command
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Store a setting",
+	Args:  usageArgs(cobra.ExactArgs(2)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleConfigSet(args[0], args[1], configScope)
+	},
+}
+
+// configUnsetCmd represents the config unset command
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a stored setting",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleConfigUnset(args[0], configScope)
+	},
+}
+
+// configEditCmd represents the config edit command
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit stored settings in your editor",
+	Long: `Open the stored settings of a scope as JSON in your editor. The settings
+are checked when the editor closes and only saved if all of them are valid.`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleConfigEdit(configScope)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configListCmd, configGetCmd, configSetCmd, configUnsetCmd, configEditCmd)
+	for _, cmd := range []*cobra.Command{configSetCmd, configUnsetCmd, configEditCmd} {
+		cmd.Flags().StringVar(&configScope, "scope", "global", "Where to store the setting: global or profile")
+	}
+
+	var settings strings.Builder
+	for _, s := range settingsSchema {
+		fmt.Fprintf(&settings, "  %-14s%s\n", s.key, s.description)
+	}
+	configCmd.Long = strings.Replace(configCmd.Long, "\nExamples:", "\nSettings:\n"+settings.String()+"\nExamples:", 1)
+}
+
+// isConfigCommand reports whether cmd is config or one of its subcommands
+func isConfigCommand(cmd *cobra.Command) bool {
+	for ; cmd != nil; cmd = cmd.Parent() {
+		if cmd == configCmd {
+			return true
+		}
+	}
+	return false
+}
+
+// handleConfigList prints every setting with its effective value and source
+func handleConfigList() error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range settingsSchema {
+		resolved := resolveSetting(s, config)
+
+		color.New(color.FgWhite, color.Bold).Printf("%-14s", s.key)
+		value := resolved.value
+		if value == "" {
+			value = "(not set)"
+		}
+		if err := s.validate(resolved.value); resolved.value != "" && err != nil {
+			color.New(color.FgRed).Printf("%-28s", value)
+			color.New(color.FgHiBlack).Printf("%s, invalid: %v\n", describeSettingOrigin(resolved), err)
+			continue
+		}
+		color.New(color.FgCyan).Printf("%-28s", value)
+		if resolved.source == settingSourceDefault {
+			color.New(color.FgHiBlack).Println(resolved.source)
+		} else {
+			color.New(color.FgHiBlack).Printf("%s (%s)\n", resolved.source, resolved.origin)
+		}
+	}
+
+	return nil
+}
+
+// handleConfigGet prints the effective value of a setting
+func handleConfigGet(key string) error {
+	s, err := lookupSetting(key)
+	if err != nil {
+		return err
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(resolveSetting(s, config).value)
+	return nil
+}
+
+// handleConfigSet validates and stores a setting in the given scope
+func handleConfigSet(key, value, scope string) error {
+	if err := validateSettingValue(key, value); err != nil {
+		return err
+	}
+
+	config, profile, err := loadConfigScope(scope)
+	if err != nil {
+		return err
+	}
+	if profile != nil {
+		profile.setSettingValue(key, value)
+	} else {
+		config.setSettingValue(key, value)
+	}
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen).Printf("⧗ set %s to %s", key, value)
+	color.New(color.FgHiBlack).Printf(" in %s\n", describeConfigScope(config, profile))
+	warnOverriddenSetting(key, scope)
+	return nil
+}
+
+// handleConfigUnset removes a setting from the given scope
+func handleConfigUnset(key, scope string) error {
+	if _, err := lookupSetting(key); err != nil {
+		return err
+	}
+
+	config, profile, err := loadConfigScope(scope)
+	if err != nil {
+		return err
+	}
+	if profile != nil {
+		if key == "api-url" {
+			return validationError("a profile always has an API URL, set another one instead")
+		}
+		profile.setSettingValue(key, "")
+	} else {
+		config.setSettingValue(key, "")
+	}
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen).Printf("⧗ unset %s", key)
+	color.New(color.FgHiBlack).Printf(" in %s\n", describeConfigScope(config, profile))
+	return nil
+}
+
+// handleConfigEdit opens the settings of a scope in the editor and saves them
+// if they are all valid
+func handleConfigEdit(scope string) error {
+	if !stdinIsTerminal() {
+		return validationError("stdin is not a terminal, can't open an editor")
+	}
+
+	config, profile, err := loadConfigScope(scope)
+	if err != nil {
+		return err
+	}
+
+	settings := map[string]string{}
+	for _, s := range settingsSchema {
+		if profile != nil {
+			if value := profile.settingValue(s.key); value != "" {
+				settings[s.key] = value
+			}
+		} else if value := config.Settings[s.key]; value != "" {
+			settings[s.key] = value
+		}
+	}
+	original, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to prepare settings: %v", err)
+	}
+
+	tempFile, err := os.CreateTemp("", "asyncstatus-config-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+	if _, err := tempFile.Write(append(original, '\n')); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+
+	if err := openEditor(tempFile.Name()); err != nil {
+		return fmt.Errorf("failed to open editor: %v", err)
+	}
+
+	content, err := os.ReadFile(tempFile.Name())
+	if err != nil {
+		return fmt.Errorf("failed to read edited settings: %v", err)
+	}
+	if bytes.Equal(bytes.TrimSpace(content), original) {
+		color.New(color.FgHiBlack).Println("⧗ no changes made")
+		return nil
+	}
+
+	var edited map[string]string
+	if err := json.Unmarshal(content, &edited); err != nil {
+		return validationError("edited settings are not a JSON object of strings: %v", err)
+	}
+	keys := make([]string, 0, len(edited))
+	for key := range edited {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := validateSettingValue(key, edited[key]); err != nil {
+			return err
+		}
+	}
+	if profile != nil && edited["api-url"] == "" {
+		return validationError("a profile always has an API URL, keep api-url in the settings")
+	}
+
+	for _, s := range settingsSchema {
+		if profile != nil {
+			profile.setSettingValue(s.key, edited[s.key])
+		} else {
+			config.setSettingValue(s.key, edited[s.key])
+		}
+	}
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen).Print("⧗ settings saved")
+	color.New(color.FgHiBlack).Printf(" in %s\n", describeConfigScope(config, profile))
+	return nil
+}
+
+// loadConfigScope loads the config and, for the profile scope, the active
+// profile whose settings are changed
+func loadConfigScope(scope string) (*Config, *Profile, error) {
+	if scope != "global" && scope != "profile" {
+		return nil, nil, validationError("invalid --scope %q, expected global or profile", scope)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	if scope == "global" {
+		return config, nil, nil
+	}
+
+	profile := config.activeProfile()
+	if profile == nil {
+		return nil, nil, validationError("profile %q doesn't exist yet, log in to create it", config.activeProfileName())
+	}
+	return config, profile, nil
+}
+
+// describeConfigScope names where a setting was stored
+func describeConfigScope(config *Config, profile *Profile) string {
+	if profile != nil {
+		return fmt.Sprintf("profile %s", config.activeProfileName())
+	}
+	return "global settings"
+}
+
+// warnOverriddenSetting notes when a stored setting has no effect because a
+// flag, variable or profile setting takes precedence
+func warnOverriddenSetting(key, scope string) {
+	s, err := lookupSetting(key)
+	if err != nil {
+		return
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return
+	}
+
+	resolved := resolveSetting(s, config)
+	if resolved.source != scope && resolved.source != settingSourceDefault {
+		color.New(color.FgYellow).Printf("  note: %s from %s still takes precedence\n", key, describeSettingOrigin(resolved))
+		if key == "api-url" && resolved.source == settingSourceProfile {
+			color.New(color.FgHiBlack).Println("  a profile keeps the API URL it logged in to; the global one applies to new profiles")
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var forceDeleteContext bool
+
+// profileNameRegex restricts profile names to what is safe as a directory name
+var profileNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// contextCmd represents the context command
+var contextCmd = &cobra.Command{
+	Use:     "context",
+	Aliases: []string{"profile"},
+	Short:   "Manage profiles for different servers and accounts",
+	Long: `Manage named profiles, each bundling an API URL, credentials and a default
+organization, similar to kubectl contexts.
+
+Profiles are created by logging in with --profile. Every command uses the profile
+selected by, in order: the --profile flag, the ASYNCSTATUS_PROFILE environment
+variable, or "asyncstatus context use".
+
+Examples:
+  asyncstatus login --profile staging --api-url https://staging.api.asyncstatus.com
+  asyncstatus context list
+  asyncstatus context use staging
+  asyncstatus context rename staging stg
+  asyncstatus context delete stg
+  asyncstatus --profile prod show`,
+}
+
+// contextListCmd represents the context list command
+var contextListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleContextList()
+	},
+}
+
+// contextUseCmd represents the context use command
+var contextUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Switch the current profile",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleContextUse(args[0])
+	},
+}
+
+// contextRenameCmd represents the context rename command
+var contextRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a profile",
+	Args:  usageArgs(cobra.ExactArgs(2)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleContextRename(args[0], args[1])
+	},
+}
+
+// contextDeleteCmd represents the context delete command
+var contextDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a profile and its local data",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleContextDelete(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(contextCmd)
+	contextCmd.AddCommand(contextListCmd, contextUseCmd, contextRenameCmd, contextDeleteCmd)
+	contextDeleteCmd.Flags().BoolVar(&forceDeleteContext, "force", false, "Delete even if the profile has unsynced items")
+}
+
+// handleContextList prints all profiles, marking the active one
+func handleContextList() error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if len(config.Profiles) == 0 {
+		color.New(color.FgHiBlack).Println("⧗ no profiles yet")
+		color.New(color.FgHiBlack).Println("  run:", color.New(color.FgWhite).Sprint("asyncstatus login"), "to create one")
+		return nil
+	}
+
+	active := config.activeProfileName()
+	for _, name := range config.profileNames() {
+		profile := config.Profiles[name]
+
+		if name == active {
+			color.New(color.FgGreen).Print("* ")
+			color.New(color.FgWhite, color.Bold).Print(name)
+		} else {
+			fmt.Print("  ")
+			color.New(color.FgWhite).Print(name)
+		}
+		color.New(color.FgHiBlack).Printf("  %s", profile.APIURL)
+		if profile.Email != "" {
+			color.New(color.FgCyan).Printf("  %s", profile.Email)
+		} else {
+			color.New(color.FgHiBlack).Print("  (logged out)")
+		}
+		if profile.Organization != "" {
+			color.New(color.FgMagenta).Printf("  %s", profile.Organization)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// handleContextUse makes a profile the current one
+func handleContextUse(name string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if config.Profiles[name] == nil {
+		return validationError("profile %q not found", name)
+	}
+
+	config.CurrentProfile = name
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen).Print("⧗ switched to profile ")
+	color.New(color.FgWhite).Println(name)
+	if env := os.Getenv("ASYNCSTATUS_PROFILE"); env != "" && env != name {
+		color.New(color.FgHiBlack).Printf("  note: ASYNCSTATUS_PROFILE=%s still takes precedence in this shell\n", env)
+	}
+	return nil
+}
+
+// handleContextRename renames a profile and moves its local data
+func handleContextRename(oldName, newName string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	profile := config.Profiles[oldName]
+	if profile == nil {
+		return validationError("profile %q not found", oldName)
+	}
+	if !profileNameRegex.MatchString(newName) {
+		return validationError("invalid profile name %q, use letters, digits, '.', '_' and '-'", newName)
+	}
+	if config.Profiles[newName] != nil {
+		return validationError("profile %q already exists", newName)
+	}
+
+	// Credentials may be tied to the profile name, so move them explicitly
+	store, err := getCredentialStore(config)
+	if err != nil {
+		return err
+	}
+	creds, err := store.get(oldName, profile)
+	if err != nil {
+		return err
+	}
+
+	oldDirs, newDirs := getProfileDataDirs(oldName), getProfileDataDirs(newName)
+	for i, dir := range oldDirs {
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(newDirs[i]), 0700); err != nil {
+			return fmt.Errorf("failed to move profile data: %v", err)
+		}
+		if err := os.Rename(dir, newDirs[i]); err != nil {
+			return fmt.Errorf("failed to move profile data: %v", err)
+		}
+	}
+
+	if err := eraseCredentials(config, oldName); err != nil {
+		return err
+	}
+	delete(config.Profiles, oldName)
+	config.Profiles[newName] = profile
+	if config.CurrentProfile == oldName {
+		config.CurrentProfile = newName
+	}
+	if creds != nil {
+		if err := saveCredentials(config, newName, creds); err != nil {
+			return err
+		}
+	} else if err := saveConfig(config); err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen).Printf("⧗ renamed profile %s to %s\n", oldName, newName)
+	return nil
+}
+
+// handleContextDelete removes a profile and its local data
+func handleContextDelete(name string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if config.Profiles[name] == nil {
+		return validationError("profile %q not found", name)
+	}
+
+	if !forceDeleteContext {
+		// The profile's own outbox, even when ASYNCSTATUS_TOKEN points the
+		// active one elsewhere
+		outbox, err := loadOutboxFile(profileOutboxPath(getProfileDir(name)))
+		if err != nil {
+			return err
+		}
+		if len(outbox.Items) > 0 {
+			return validationError("profile %q has %d unsynced item(s), run asyncstatus --profile %s sync or use --force", name, len(outbox.Items), name)
+		}
+	}
+
+	if err := eraseCredentials(config, name); err != nil {
+		return err
+	}
+	for _, dir := range getProfileDataDirs(name) {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("failed to remove profile data: %v", err)
+		}
+	}
+
+	delete(config.Profiles, name)
+	if config.CurrentProfile == name {
+		config.CurrentProfile = ""
+	}
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen).Printf("⧗ deleted profile %s\n", name)
+	return nil
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var migrateCredentialsTo string
+
+// credentialsCmd represents the credentials command
+var credentialsCmd = &cobra.Command{
+	Use:   "credentials",
+	Short: "Manage where tokens are stored",
+	Long: `Manage where tokens and sessions are stored.
+
+Backends:
+  plaintext  in config.json, readable by your user only (default)
+  file       in a passphrase-encrypted file per profile; the passphrase is read
+             from ASYNCSTATUS_PASSPHRASE or prompted for
+  <name>     an external program asyncstatus-credential-<name> on your PATH,
+             speaking the git credential helper protocol (get, store, erase)
+
+The backend is selected by ASYNCSTATUS_CREDENTIAL_STORE or the config file,
+which "asyncstatus credentials migrate" updates.
+
+Examples:
+  asyncstatus credentials
+  asyncstatus credentials migrate --to file
+  asyncstatus credentials migrate --to pass
+  asyncstatus credentials migrate --to plaintext`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleCredentialsStatus()
+	},
+}
+
+// credentialsMigrateCmd represents the credentials migrate command
+var credentialsMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move stored credentials to another backend",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleCredentialsMigrate(migrateCredentialsTo)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(credentialsCmd)
+	credentialsCmd.AddCommand(credentialsMigrateCmd)
+
+	// The config file moves with XDG_CONFIG_HOME
+	credentialsCmd.Long = strings.Replace(credentialsCmd.Long, "in config.json", "in "+getConfigPath(), 1)
+	credentialsMigrateCmd.Flags().StringVar(&migrateCredentialsTo, "to", "", "Backend to move credentials to (plaintext, file or a helper name)")
+	credentialsMigrateCmd.MarkFlagRequired("to")
+}
+
+// handleCredentialsStatus prints the configured backend
+func handleCredentialsStatus() error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := getCredentialStore(config)
+	if err != nil {
+		return err
+	}
+
+	color.New(color.FgHiBlack).Print("⧗ credentials stored in ")
+	color.New(color.FgWhite).Println(describeCredentialStore(store))
+	return nil
+}
+
+// handleCredentialsMigrate moves the credentials of every profile to another
+// backend. Credentials are written to the new backend before any are erased
+// from the old one, so a failure leaves them readable where they were.
+func handleCredentialsMigrate(target string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	from, err := getCredentialStore(config)
+	if err != nil {
+		return err
+	}
+	to, err := newCredentialStore(target)
+	if err != nil {
+		return err
+	}
+	if from.name() == to.name() {
+		color.New(color.FgHiBlack).Printf("⧗ credentials are already stored in %s\n", describeCredentialStore(to))
+		return nil
+	}
+
+	color.New(color.FgHiBlack).Printf("⧗ moving credentials from %s to %s...\n", describeCredentialStore(from), describeCredentialStore(to))
+
+	var moved []string
+	for _, name := range config.profileNames() {
+		profile := config.Profiles[name]
+		creds, err := from.get(name, profile)
+		if err != nil {
+			return err
+		}
+		if creds == nil {
+			continue
+		}
+		if err := to.store(name, profile, creds); err != nil {
+			return err
+		}
+		moved = append(moved, name)
+		color.New(color.FgGreen).Print("  ✓ ")
+		color.New(color.FgWhite).Println(name)
+	}
+
+	config.CredentialStore = to.name()
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+
+	for _, name := range moved {
+		if err := from.erase(name, config.Profiles[name]); err != nil {
+			color.New(color.FgYellow).Printf("  could not erase %s from %s: %v\n", name, from.name(), err)
+		}
+	}
+	// The plaintext backend keeps credentials in the config itself
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen).Printf("⧗ %d profile(s) moved to %s\n", len(moved), describeCredentialStore(to))
+	return nil
+}
+
+// describeCredentialStore returns a short human description of a backend
+func describeCredentialStore(store credentialStore) string {
+	switch store.name() {
+	case credentialStorePlaintext:
+		return "plaintext config file"
+	case credentialStoreFile:
+		return "encrypted file"
+	}
+	return "helper asyncstatus-credential-" + store.name()
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// credentialStorePlaintext keeps credentials in config.json
+	credentialStorePlaintext = "plaintext"
+	// credentialStoreFile keeps credentials in a passphrase-encrypted file per profile
+	credentialStoreFile = "file"
+)
+
+// storedCredentials are the secrets of a profile
+type storedCredentials struct {
+	Token         string `json:"token"`
+	SessionCookie string `json:"sessionCookie,omitempty"`
+}
+
+// credentialStore keeps the secrets of profiles. The profile passed in is
+// the entry of the loaded config, which the caller saves afterwards.
+type credentialStore interface {
+	// name identifies the backend in the config file
+	name() string
+	// get returns the credentials of a profile, or nil if there are none
+	get(profileName string, profile *Profile) (*storedCredentials, error)
+	// store saves the credentials of a profile, replacing existing ones
+	store(profileName string, profile *Profile, creds *storedCredentials) error
+	// erase removes the credentials of a profile, if any
+	erase(profileName string, profile *Profile) error
+}
+
+// credentialsMemo holds credentials already read in this process, so
+// backends that prompt for a passphrase or run a helper do it only once
+var credentialsMemo = map[string]*storedCredentials{}
+
+// credentialStoreName returns the configured backend: ASYNCSTATUS_CREDENTIAL_STORE,
+// then the config file, then plaintext
+func credentialStoreName(config *Config) string {
+	if name := os.Getenv("ASYNCSTATUS_CREDENTIAL_STORE"); name != "" {
+		return name
+	}
+	if config.CredentialStore != "" {
+		return config.CredentialStore
+	}
+	return credentialStorePlaintext
+}
+
+// newCredentialStore returns the backend with the given name. Names other
+// than plaintext and file select the helper asyncstatus-credential-<name>.
+func newCredentialStore(name string) (credentialStore, error) {
+	switch name {
+	case credentialStorePlaintext:
+		return plaintextCredentialStore{}, nil
+	case credentialStoreFile:
+		return &fileCredentialStore{}, nil
+	}
+
+	if strings.ContainsAny(name, `/\ `) {
+		return nil, validationError("invalid credential store %q, expected plaintext, file or a helper name", name)
+	}
+	return &helperCredentialStore{helper: name}, nil
+}
+
+// getCredentialStore returns the backend configured for config
+func getCredentialStore(config *Config) (credentialStore, error) {
+	return newCredentialStore(credentialStoreName(config))
+}
+
+// tokenFromEnv returns the token set in ASYNCSTATUS_TOKEN, which replaces
+// the stored credentials of every profile
+func tokenFromEnv() string {
+	return strings.TrimSpace(os.Getenv("ASYNCSTATUS_TOKEN"))
+}
+
+// loadCredentials returns the credentials of the active profile, or an
+// authentication error if there are none. ASYNCSTATUS_TOKEN takes precedence
+// and doesn't need a config file.
+func loadCredentials() (*storedCredentials, error) {
+	if token := tokenFromEnv(); token != "" {
+		return &storedCredentials{Token: token}, nil
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return nil, authError("not authenticated: %v", err)
+	}
+
+	profile, name, err := loadActiveProfile()
+	if err != nil {
+		return nil, err
+	}
+	if creds, ok := credentialsMemo[name]; ok {
+		return creds, nil
+	}
+
+	store, err := getCredentialStore(config)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := store.get(name, profile)
+	if err != nil {
+		return nil, err
+	}
+	if creds == nil || creds.Token == "" {
+		return nil, authError("no authentication token found")
+	}
+
+	credentialsMemo[name] = creds
+	return creds, nil
+}
+
+// saveCredentials stores the credentials of a profile in config and saves config
+func saveCredentials(config *Config, profileName string, creds *storedCredentials) error {
+	store, err := getCredentialStore(config)
+	if err != nil {
+		return err
+	}
+
+	if err := store.store(profileName, config.Profiles[profileName], creds); err != nil {
+		return fmt.Errorf("failed to store credentials in %s: %w", store.name(), err)
+	}
+	credentialsMemo[profileName] = creds
+
+	return saveConfig(config)
+}
+
+// eraseCredentials removes the credentials of a profile in config without saving config
+func eraseCredentials(config *Config, profileName string) error {
+	store, err := getCredentialStore(config)
+	if err != nil {
+		return err
+	}
+
+	delete(credentialsMemo, profileName)
+	if err := store.erase(profileName, config.Profiles[profileName]); err != nil {
+		return fmt.Errorf("failed to erase credentials from %s: %w", store.name(), err)
+	}
+
+	return nil
+}
+
+// plaintextCredentialStore keeps credentials unencrypted in config.json,
+// protected by file permissions only
+type plaintextCredentialStore struct{}
+
+func (plaintextCredentialStore) name() string {
+	return credentialStorePlaintext
+}
+
+func (plaintextCredentialStore) get(profileName string, profile *Profile) (*storedCredentials, error) {
+	if profile.Token == "" {
+		return nil, nil
+	}
+	return &storedCredentials{Token: profile.Token, SessionCookie: profile.SessionCookie}, nil
+}
+
+func (plaintextCredentialStore) store(profileName string, profile *Profile, creds *storedCredentials) error {
+	profile.Token = creds.Token
+	profile.SessionCookie = creds.SessionCookie
+	return nil
+}
+
+func (plaintextCredentialStore) erase(profileName string, profile *Profile) error {
+	profile.Token = ""
+	profile.SessionCookie = ""
+	return nil
+}
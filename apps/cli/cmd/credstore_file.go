@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// scrypt parameters for deriving the file key from the passphrase
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// encryptedCredentials is the on-disk format of the encrypted credential file
+type encryptedCredentials struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// fileCredentialStore keeps credentials in profiles/<name>/credentials.enc,
+// encrypted with AES-256-GCM under a key derived from a passphrase with scrypt.
+// The passphrase is read from ASYNCSTATUS_PASSPHRASE or prompted for once per run.
+type fileCredentialStore struct{}
+
+// filePassphrase holds the passphrase already read in this process, shared by
+// every fileCredentialStore and profile so it is asked for once
+var filePassphrase string
+
+// getCredentialFilePath returns the path of the encrypted credential file of a profile
+func getCredentialFilePath(profileName string) string {
+	return filepath.Join(getProfileDir(profileName), "credentials.enc")
+}
+
+func (s *fileCredentialStore) name() string {
+	return credentialStoreFile
+}
+
+func (s *fileCredentialStore) get(profileName string, profile *Profile) (*storedCredentials, error) {
+	content, err := os.ReadFile(getCredentialFilePath(profileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+
+	var file encryptedCredentials
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("invalid credentials file format: %v", err)
+	}
+	if file.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported credentials key derivation %q", file.KDF)
+	}
+
+	passphrase, err := s.readPassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newCredentialCipher(passphrase, file.Salt, file.N, file.R, file.P)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, []byte(profileName))
+	if err != nil {
+		// A wrong passphrase isn't kept, so the next attempt asks again
+		filePassphrase = ""
+		return nil, authError("could not decrypt credentials of profile %q, wrong passphrase?", profileName)
+	}
+
+	var creds storedCredentials
+	if err := json.Unmarshal(plaintext, &creds); err != nil {
+		return nil, fmt.Errorf("invalid credentials: %v", err)
+	}
+
+	return &creds, nil
+}
+
+func (s *fileCredentialStore) store(profileName string, profile *Profile, creds *storedCredentials) error {
+	_, statErr := os.Stat(getCredentialFilePath(profileName))
+	passphrase, err := s.readPassphrase(errors.Is(statErr, os.ErrNotExist))
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(creds)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %v", err)
+	}
+
+	file := encryptedCredentials{
+		Version: 1,
+		KDF:     "scrypt",
+		N:       scryptN,
+		R:       scryptR,
+		P:       scryptP,
+		Salt:    make([]byte, 16),
+	}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %v", err)
+	}
+
+	gcm, err := newCredentialCipher(passphrase, file.Salt, file.N, file.R, file.P)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	// The profile name is authenticated so a file can't be swapped between profiles
+	file.Ciphertext = gcm.Seal(nil, file.Nonce, plaintext, []byte(profileName))
+
+	jsonData, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %v", err)
+	}
+	if err := os.MkdirAll(getProfileDir(profileName), 0700); err != nil {
+		return fmt.Errorf("failed to create profile directory: %v", err)
+	}
+
+	return writeFileAtomic(getCredentialFilePath(profileName), jsonData, 0600)
+}
+
+func (s *fileCredentialStore) erase(profileName string, profile *Profile) error {
+	err := os.Remove(getCredentialFilePath(profileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// readPassphrase returns the passphrase from ASYNCSTATUS_PASSPHRASE or the
+// terminal, once per run. When confirm is set and none was given yet, a new
+// passphrase is asked for twice.
+func (s *fileCredentialStore) readPassphrase(confirm bool) (string, error) {
+	if filePassphrase != "" {
+		return filePassphrase, nil
+	}
+	if passphrase := os.Getenv("ASYNCSTATUS_PASSPHRASE"); passphrase != "" {
+		filePassphrase = passphrase
+		return passphrase, nil
+	}
+
+	if !stdinIsTerminal() {
+		return "", validationError("a passphrase is needed to unlock the credential file, set ASYNCSTATUS_PASSPHRASE")
+	}
+
+	prompt := "credentials passphrase: "
+	if confirm {
+		prompt = "new credentials passphrase: "
+	}
+	passphrase, err := promptSecret(prompt)
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", validationError("passphrase is required")
+	}
+
+	if confirm {
+		again, err := promptSecret("repeat passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", validationError("passphrases don't match")
+		}
+	}
+
+	filePassphrase = passphrase
+	return passphrase, nil
+}
+
+// promptSecret reads a line from the terminal without echoing it.
+// The prompt goes to stderr so it doesn't mix with command output.
+func promptSecret(prompt string) (string, error) {
+	color.New(color.FgHiBlack).Fprint(os.Stderr, prompt)
+	secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("error reading passphrase: %w", err)
+	}
+	return string(secret), nil
+}
+
+// newCredentialCipher derives the file key from the passphrase and returns an AES-GCM cipher
+func newCredentialCipher(passphrase string, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, n, r, p, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %v", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %v", err)
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// helperCredentialStore delegates to an external program named
+// asyncstatus-credential-<helper>, speaking the git credential helper protocol:
+// the program is run with get, store or erase as its argument and reads
+// key=value lines terminated by a blank line on stdin. For get it prints the
+// secret as password=<value>. The token and the session cookie are kept as two
+// entries, told apart by their path.
+type helperCredentialStore struct {
+	helper string
+}
+
+// helperCredentialKinds are the entries kept per profile, by path suffix
+const (
+	helperCredentialToken   = "token"
+	helperCredentialSession = "session"
+)
+
+func (s *helperCredentialStore) name() string {
+	return s.helper
+}
+
+func (s *helperCredentialStore) get(profileName string, profile *Profile) (*storedCredentials, error) {
+	token, err := s.getSecret(profileName, profile, helperCredentialToken)
+	if err != nil || token == "" {
+		return nil, err
+	}
+
+	session, err := s.getSecret(profileName, profile, helperCredentialSession)
+	if err != nil {
+		return nil, err
+	}
+
+	return &storedCredentials{Token: token, SessionCookie: session}, nil
+}
+
+func (s *helperCredentialStore) store(profileName string, profile *Profile, creds *storedCredentials) error {
+	if err := s.run("store", s.attributes(profileName, profile, helperCredentialToken, creds.Token), nil); err != nil {
+		return err
+	}
+
+	if creds.SessionCookie == "" {
+		return s.run("erase", s.attributes(profileName, profile, helperCredentialSession, ""), nil)
+	}
+	return s.run("store", s.attributes(profileName, profile, helperCredentialSession, creds.SessionCookie), nil)
+}
+
+func (s *helperCredentialStore) erase(profileName string, profile *Profile) error {
+	if err := s.run("erase", s.attributes(profileName, profile, helperCredentialToken, ""), nil); err != nil {
+		return err
+	}
+	return s.run("erase", s.attributes(profileName, profile, helperCredentialSession, ""), nil)
+}
+
+// getSecret asks the helper for one entry and returns "" if it has none
+func (s *helperCredentialStore) getSecret(profileName string, profile *Profile, kind string) (string, error) {
+	response := map[string]string{}
+	if err := s.run("get", s.attributes(profileName, profile, kind, ""), response); err != nil {
+		return "", err
+	}
+	return response["password"], nil
+}
+
+// attributes describes an entry to the helper like git describes a remote:
+// the API host, a path naming the profile and entry, and the account email
+func (s *helperCredentialStore) attributes(profileName string, profile *Profile, kind, password string) [][2]string {
+	protocol, host := "https", profile.APIURL
+	if apiURL, err := url.Parse(profile.APIURL); err == nil && apiURL.Host != "" {
+		protocol, host = apiURL.Scheme, apiURL.Host
+	}
+
+	attributes := [][2]string{
+		{"protocol", protocol},
+		{"host", host},
+		{"path", "asyncstatus/" + profileName + "/" + kind},
+	}
+	if profile.Email != "" {
+		attributes = append(attributes, [2]string{"username", profile.Email})
+	}
+	if password != "" {
+		attributes = append(attributes, [2]string{"password", password})
+	}
+	return attributes
+}
+
+// run invokes the helper with an action and attributes, collecting its
+// key=value output into response when it is not nil
+func (s *helperCredentialStore) run(action string, attributes [][2]string, response map[string]string) error {
+	program := "asyncstatus-credential-" + s.helper
+	path, err := exec.LookPath(program)
+	if err != nil {
+		return validationError("credential helper %s not found in PATH", program)
+	}
+
+	var input bytes.Buffer
+	for _, attribute := range attributes {
+		if strings.ContainsAny(attribute[1], "\n\x00") {
+			return fmt.Errorf("invalid %s for credential helper", attribute[0])
+		}
+		fmt.Fprintf(&input, "%s=%s\n", attribute[0], attribute[1])
+	}
+	input.WriteString("\n")
+
+	var output bytes.Buffer
+	cmd := exec.Command(path, action)
+	cmd.Stdin = &input
+	cmd.Stdout = &output
+	// Helpers may prompt, for example to unlock a vault
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("credential helper %s %s failed with exit code %d", program, action, exitErr.ExitCode())
+		}
+		return fmt.Errorf("credential helper %s %s failed: %v", program, action, err)
+	}
+
+	if response == nil {
+		return nil
+	}
+
+	scanner := bufio.NewScanner(&output)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			response[key] = value
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxShowRangeDays is the longest range show accepts
+const maxShowRangeDays = 92
+
+// maxEditRangeDays is the longest range edit opens in one buffer
+const maxEditRangeDays = 31
+
+// dateExprExamples is suggested when a date expression isn't understood
+const dateExprExamples = "today, yesterday, friday, last monday, last week, start of month, -3d, 2 weeks ago, 2024-01-15, 2024-W03 or 2024-01-15..2024-01-19"
+
+// dateRange is an inclusive range of days. Both ends are midnight in the
+// user's zone.
+type dateRange struct {
+	start time.Time
+	end   time.Time
+}
+
+// singleDay reports whether the range covers exactly one day
+func (r dateRange) singleDay() bool {
+	return r.start.Equal(r.end)
+}
+
+// days returns the number of days in the range
+func (r dateRange) days() int {
+	return len(r.dates())
+}
+
+// dates returns the days of the range as YYYY-MM-DD, oldest first
+func (r dateRange) dates() []string {
+	var dates []string
+	for day := r.start; !day.After(r.end); day = day.AddDate(0, 0, 1) {
+		dates = append(dates, day.Format("2006-01-02"))
+	}
+	return dates
+}
+
+// String formats the range as it would be typed
+func (r dateRange) String() string {
+	if r.singleDay() {
+		return r.start.Format("2006-01-02")
+	}
+	return r.start.Format("2006-01-02") + ".." + r.end.Format("2006-01-02")
+}
+
+var (
+	isoDateRegex      = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	isoWeekRegex      = regexp.MustCompile(`^(\d{4})-w(\d{2})(?:-([1-7]))?$`)
+	isoMonthRegex     = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+	offsetRegex       = regexp.MustCompile(`^([+-])(\d+)\s*([dwmy])$`)
+	agoRegex          = regexp.MustCompile(`^(\d+)\s+(day|week|month|year)s?\s+ago$`)
+	weekdayRegex      = regexp.MustCompile(`^(?:(last|this)\s+)?([a-z]+)$`)
+	periodRegex       = regexp.MustCompile(`^(this|last)\s+(week|month|year)$`)
+	periodEdgeRegex   = regexp.MustCompile(`^(start|end)\s+of\s+(?:(this|last)\s+)?(week|month|year)$`)
+	slashedDateRegex  = regexp.MustCompile(`^\d{1,4}[/.]\d{1,2}[/.]\d{1,4}$`)
+	whitespaceRegex   = regexp.MustCompile(`\s+`)
+	weekdaysByName    = map[string]time.Weekday{}
+	periodUnitsByName = map[string]string{"d": "day", "w": "week", "m": "month", "y": "year"}
+)
+
+func init() {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		weekdaysByName[name] = day
+		weekdaysByName[name[:3]] = day
+	}
+	weekdaysByName["tues"] = time.Tuesday
+	weekdaysByName["wed"] = time.Wednesday
+	weekdaysByName["thur"] = time.Thursday
+	weekdaysByName["thurs"] = time.Thursday
+}
+
+// parseDateExpr parses a date expression relative to now: a single day such
+// as "friday" or "-3d", a period such as "last week" or "2024-W03", or an
+// inclusive range "<from>..<to>" of two such expressions
+func parseDateExpr(expr string, now time.Time) (dateRange, error) {
+	normalized := whitespaceRegex.ReplaceAllString(strings.ToLower(strings.TrimSpace(expr)), " ")
+	if normalized == "" {
+		return dateRange{}, validationError("empty date, try: %s", dateExprExamples)
+	}
+
+	if from, to, ok := strings.Cut(normalized, ".."); ok {
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if from == "" || to == "" {
+			return dateRange{}, validationError("range %q needs a start and an end, e.g. 2024-01-15..2024-01-19 or monday..today", expr)
+		}
+		start, err := parseDatePeriod(from, now)
+		if err != nil {
+			return dateRange{}, err
+		}
+		end, err := parseDatePeriod(to, now)
+		if err != nil {
+			return dateRange{}, err
+		}
+		if end.end.Before(start.start) {
+			return dateRange{}, validationError("range %q ends before it starts", expr)
+		}
+		return dateRange{start: start.start, end: end.end}, nil
+	}
+
+	return parseDatePeriod(normalized, now)
+}
+
+// parseDatePeriod parses one side of a range, which may itself cover several days
+func parseDatePeriod(expr string, now time.Time) (dateRange, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day := func(t time.Time) (dateRange, error) {
+		return dateRange{start: t, end: t}, nil
+	}
+
+	switch expr {
+	case "today", "now":
+		return day(today)
+	case "yesterday":
+		return day(today.AddDate(0, 0, -1))
+	case "tomorrow":
+		return day(today.AddDate(0, 0, 1))
+	}
+
+	if matches := isoDateRegex.FindStringSubmatch(expr); matches != nil {
+		parsed, err := time.ParseInLocation("2006-01-02", expr, now.Location())
+		if err != nil {
+			return dateRange{}, validationError("%q is not a valid date", expr)
+		}
+		return day(parsed)
+	}
+
+	if matches := isoWeekRegex.FindStringSubmatch(expr); matches != nil {
+		year, _ := strconv.Atoi(matches[1])
+		week, _ := strconv.Atoi(matches[2])
+		monday, err := isoWeekStart(year, week, now.Location())
+		if err != nil {
+			return dateRange{}, err
+		}
+		if matches[3] != "" {
+			weekday, _ := strconv.Atoi(matches[3])
+			return day(monday.AddDate(0, 0, weekday-1))
+		}
+		return dateRange{start: monday, end: monday.AddDate(0, 0, 6)}, nil
+	}
+
+	if matches := isoMonthRegex.FindStringSubmatch(expr); matches != nil {
+		year, _ := strconv.Atoi(matches[1])
+		month, _ := strconv.Atoi(matches[2])
+		if month < 1 || month > 12 {
+			return dateRange{}, validationError("%q is not a valid month", expr)
+		}
+		start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, now.Location())
+		return dateRange{start: start, end: start.AddDate(0, 1, -1)}, nil
+	}
+
+	if matches := offsetRegex.FindStringSubmatch(expr); matches != nil {
+		n, _ := strconv.Atoi(matches[2])
+		if matches[1] == "-" {
+			n = -n
+		}
+		return day(addDateUnits(today, periodUnitsByName[matches[3]], n))
+	}
+
+	if matches := agoRegex.FindStringSubmatch(expr); matches != nil {
+		n, _ := strconv.Atoi(matches[1])
+		return day(addDateUnits(today, matches[2], -n))
+	}
+
+	if matches := periodRegex.FindStringSubmatch(expr); matches != nil {
+		return periodContaining(today, matches[2], matches[1] == "last"), nil
+	}
+
+	if matches := periodEdgeRegex.FindStringSubmatch(expr); matches != nil {
+		period := periodContaining(today, matches[3], matches[2] == "last")
+		if matches[1] == "start" {
+			return day(period.start)
+		}
+		return day(period.end)
+	}
+
+	if matches := weekdayRegex.FindStringSubmatch(expr); matches != nil {
+		if weekday, ok := weekdaysByName[matches[2]]; ok {
+			return day(resolveWeekday(today, weekday, matches[1]))
+		}
+	}
+
+	if slashedDateRegex.MatchString(expr) {
+		return dateRange{}, validationError("%q is ambiguous, write dates as YYYY-MM-DD, e.g. 2024-01-15", expr)
+	}
+	if expr == "last" || expr == "this" || expr == "next" || strings.HasPrefix(expr, "next ") {
+		return dateRange{}, validationError("%q is not supported, try: %s", expr, dateExprExamples)
+	}
+	return dateRange{}, validationError("unrecognized date %q, try: %s", expr, dateExprExamples)
+}
+
+// isoWeekStart returns the Monday of an ISO 8601 week
+func isoWeekStart(year, week int, location *time.Location) (time.Time, error) {
+	// January 4th is always in week 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, location)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday())+6)%7)+(week-1)*7)
+	if week < 1 {
+		return time.Time{}, validationError("%d-W%02d is not a valid ISO week", year, week)
+	}
+	if y, w := monday.ISOWeek(); y != year || w != week {
+		return time.Time{}, validationError("%d has no ISO week %d", year, week)
+	}
+	return monday, nil
+}
+
+// addDateUnits adds n days, weeks, months or years to a day
+func addDateUnits(day time.Time, unit string, n int) time.Time {
+	switch unit {
+	case "week":
+		return day.AddDate(0, 0, 7*n)
+	case "month":
+		return day.AddDate(0, n, 0)
+	case "year":
+		return day.AddDate(n, 0, 0)
+	}
+	return day.AddDate(0, 0, n)
+}
+
+// periodContaining returns the week (Monday to Sunday), month or year
+// containing today, or the one before it if previous is set
+func periodContaining(today time.Time, unit string, previous bool) dateRange {
+	var start time.Time
+	switch unit {
+	case "week":
+		start = today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		if previous {
+			start = start.AddDate(0, 0, -7)
+		}
+		return dateRange{start: start, end: start.AddDate(0, 0, 6)}
+	case "month":
+		start = time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+		if previous {
+			start = start.AddDate(0, -1, 0)
+		}
+		return dateRange{start: start, end: start.AddDate(0, 1, -1)}
+	}
+	start = time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, today.Location())
+	if previous {
+		start = start.AddDate(-1, 0, 0)
+	}
+	return dateRange{start: start, end: start.AddDate(1, 0, -1)}
+}
+
+// resolveWeekday returns the day for a weekday name. A bare name is the most
+// recent such day, today included; "last" excludes today; "this" is the day
+// in the current Monday to Sunday week, which may be ahead.
+func resolveWeekday(today time.Time, weekday time.Weekday, qualifier string) time.Time {
+	switch qualifier {
+	case "this":
+		monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		return monday.AddDate(0, 0, (int(weekday)+6)%7)
+	case "last":
+		back := (int(today.Weekday()) - int(weekday) + 7) % 7
+		if back == 0 {
+			back = 7
+		}
+		return today.AddDate(0, 0, -back)
+	}
+	return today.AddDate(0, 0, -((int(today.Weekday()) - int(weekday) + 7) % 7))
+}
+
+// parseDateRange parses a date expression in the user's zone, for commands
+// that accept several days. The range ends today at the latest and covers at
+// most maxDays days, unless maxDays is 0.
+func parseDateRange(expr string, maxDays int) (dateRange, error) {
+	now := nowInTimezone()
+	r, err := parseDateExpr(expr, now)
+	if err != nil {
+		return dateRange{}, err
+	}
+	// Days after today can't have updates yet
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if r.start.After(today) {
+		return dateRange{}, validationError("%q is in the future", expr)
+	}
+	if r.end.After(today) {
+		r.end = today
+	}
+	if maxDays > 0 && r.days() > maxDays {
+		return dateRange{}, validationError("%q covers %d days, at most %d are allowed", expr, r.days(), maxDays)
+	}
+	return r, nil
+}
+
+// describeDateRange formats a range for headings
+func describeDateRange(r dateRange) string {
+	if r.singleDay() {
+		return formatDateForDisplay(r.start.Format("2006-01-02"))
+	}
+	return fmt.Sprintf("%s to %s", formatDate(r.start), formatDate(r.end))
+}
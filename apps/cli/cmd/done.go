@@ -1,11 +1,9 @@
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io"
+	"context"
 
+	"asyncstatus.com/cli/client"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -18,64 +16,31 @@ var doneCmd = &cobra.Command{
 
 Examples:
   asyncstatus done "finished the API endpoint"
-  asyncstatus done "fixed the bug in user authentication"`,
-	Args: cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := handleDoneStatus(args[0]); err != nil {
-			color.New(color.FgRed).Printf("⧗ failed: %v\n", err)
-			color.New(color.FgHiBlack).Println("  run:", color.New(color.FgWhite).Sprint("asyncstatus login"), "first")
-		}
+  asyncstatus done "fixed the bug in user authentication"
+  asyncstatus done --date yesterday "forgot to log this"`,
+	Args: usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleDoneStatus(cmd.Context(), args[0])
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(doneCmd)
+	addItemDateFlag(doneCmd)
 }
 
-// StatusUpdateRequest represents the API request for creating a status update
-type StatusUpdateRequest struct {
-	Type    string `json:"type"`
-	Message string `json:"message"`
-}
-
-
-
 // handleDoneStatus processes adding a done status update
-func handleDoneStatus(message string) error {
+func handleDoneStatus(ctx context.Context, message string) error {
 	color.New(color.FgGreen).Print("⧗ done: ")
 	color.New(color.FgWhite).Println(message)
 	
-	// Create the request payload
-	payload := StatusUpdateRequest{
-		Type:    "done",
-		Message: message,
-	}
-	
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to prepare request: %v", err)
-	}
-	
-	endpoint := "/cli/status-updates"
-	client, req, err := makeAuthenticatedJSONRequest("POST", endpoint)
+	queued, err := sendStatusUpdateItem(ctx, client.ItemTypeDone, message)
 	if err != nil {
 		return err
 	}
-	
-	// Set request body
-	req.Body = io.NopCloser(bytes.NewBuffer(jsonData))
-	
-	// Send request
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %v", err)
-	}
-	defer resp.Body.Close()
-	
-	// Check response
-	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("server error (status %d): %s", resp.StatusCode, string(body))
+	if queued {
+		printQueuedOffline()
+		return nil
 	}
 	
 	color.New(color.FgGreen).Println("  ✓ saved")
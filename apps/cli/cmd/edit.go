@@ -2,15 +2,18 @@ package cmd
 
 import (
 	"bufio"
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"regexp"
 	"strings"
+	"syscall"
+	"unicode/utf8"
 
+	"asyncstatus.com/cli/client"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -24,7 +27,7 @@ Opens a temporary file with your current status items that you can modify, reord
 add to, or remove. Changes are saved when you close the editor.
 
 Editor detection (in order of preference):
-  1. ASYNCSTATUS_EDITOR environment variable
+  1. ASYNCSTATUS_EDITOR environment variable, then the editor setting
   2. GIT_EDITOR environment variable
   3. VISUAL environment variable  
   4. EDITOR environment variable
@@ -40,119 +43,174 @@ Examples:
   asyncstatus edit "1 week ago"   # Edit status update from 1 week ago
   asyncstatus edit "3 weeks ago"  # Edit status update from 3 weeks ago
   asyncstatus edit 2024-01-15     # Edit status update for specific date
+  asyncstatus edit friday         # Edit status update from the last Friday
+  asyncstatus edit -- -3d         # Edit status update from 3 days ago
+  asyncstatus edit --resume       # Reopen an edit that failed to save
+  asyncstatus edit --range "last week"  # Edit every day of last week at once
   
 Configuration:
   export ASYNCSTATUS_EDITOR=code  # Use VS Code for AsyncStatus only
-  export ASYNCSTATUS_EDITOR="code -w"  # Use VS Code with wait flag`,
-	Args: cobra.MaximumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+  export ASYNCSTATUS_EDITOR="code -w"  # Use VS Code with wait flag
+  asyncstatus config set editor "code -w"  # Keep it in the config file instead`,
+	Args: usageArgs(cobra.MaximumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
 		var date string
 		if len(args) == 1 {
 			date = args[0]
 		}
 		
-		if err := handleEditStatus(date); err != nil {
-			color.New(color.FgRed).Printf("⧗ failed: %v\n", err)
-			color.New(color.FgHiBlack).Println("  run:", color.New(color.FgWhite).Sprint("asyncstatus login"), "first")
+		if editRangeFlag != "" {
+			if date != "" {
+				return validationError("use either a date or --range, not both")
+			}
+			return handleEditRange(cmd.Context(), editRangeFlag, editResumeFlag)
 		}
+		return handleEditStatus(cmd.Context(), date, editResumeFlag)
 	},
 }
 
+var (
+	editResumeFlag bool
+	editRangeFlag  string
+)
+
 func init() {
 	rootCmd.AddCommand(editCmd)
+	editCmd.Flags().BoolVar(&editResumeFlag, "resume", false, "Reopen an edit that wasn't saved, kept as a draft")
+	editCmd.Flags().StringVar(&editRangeFlag, "range", "", "Edit every day of a range in one buffer (e.g. \"last week\")")
 }
 
-// EditStatusUpdateRequest represents the API request for editing a status update
-type EditStatusUpdateRequest struct {
-	Items []EditStatusUpdateItem `json:"items"`
-	Date  string                 `json:"date,omitempty"`
-	Mood  *string                `json:"mood"`
-	Notes *string                `json:"notes"`
-}
+// handleEditStatus processes editing a status update interactively. The buffer
+// is kept as a draft until the server has the edit, so nothing typed is lost
+// to a rejected line, a network error or an interrupt.
+func handleEditStatus(ctx context.Context, date string, resume bool) error {
+	// The editor needs a terminal, so fail before fetching anything
+	if !stdinIsTerminal() {
+		return validationError("stdin is not a terminal, can't open an editor")
+	}
+
+	// Ctrl-C and SIGTERM cancel the upload instead of killing the process, so
+	// the draft is reported
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var normalizedDate, draftPath string
+	var statusUpdate *StatusUpdate
+	var refs map[string]string
+	if resume {
+		// Reopen the draft, with the status update it was written against
+		resumeDate, err := getResumeDate(date)
+		if err != nil {
+			return err
+		}
+		draft, ok, err := loadEditDraft(resumeDate)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return validationError("no edit draft for %s", formatDateForDisplay(resumeDate))
+		}
+		if draft.Bases != nil {
+			return editRangeDraftAndSave(ctx, resumeDate, draft.Bases, draft.Refs)
+		}
+		normalizedDate, statusUpdate, refs = resumeDate, draft.Base, draft.Refs
+		draftPath = getDraftPath(normalizedDate)
+	} else {
+		// Parse and normalize the date
+		var err error
+		normalizedDate, err = parseDate(date)
+		if err != nil {
+			return err
+		}
+		
+		// Starting over would overwrite the draft
+		if _, ok, _ := loadEditDraft(normalizedDate); ok {
+			return &cliError{
+				class:   errorClassValidation,
+				message: fmt.Sprintf("an unsaved edit for %s is kept in %s", formatDateForDisplay(normalizedDate), getDraftPath(normalizedDate)),
+				hint:    fmt.Sprintf("run: asyncstatus edit --resume %s, or delete the draft to start over", normalizedDate),
+			}
+		}
 
-// EditStatusUpdateItem represents a single item in the edit request
-type EditStatusUpdateItem struct {
-	Content string `json:"content"`
-	Type    string `json:"type"`
-	Order   int    `json:"order"`
-}
+		// Get current status update
+		statusUpdate, err = getCurrentStatusUpdateForDate(ctx, normalizedDate)
+		if err != nil {
+			return fmt.Errorf("failed to fetch status update: %w", err)
+		}
 
-// EditStatusUpdateResponse represents the API response for editing a status update
-type EditStatusUpdateResponse struct {
-	StatusUpdate *StatusUpdate `json:"statusUpdate"`
-	Message      string        `json:"message"`
-}
+		// Each item line carries a short reference, so edits keep the item's identity
+		var items []StatusUpdateItem
+		if statusUpdate != nil {
+			items = statusUpdate.Items
+		}
+		refs = itemRefs(items)
 
-// handleEditStatus processes editing a status update interactively
-func handleEditStatus(date string) error {
-	// Parse and normalize the date
-	normalizedDate, err := parseDate(date)
-	if err != nil {
-		return fmt.Errorf("invalid date format: %v", err)
+		// Create the draft with editable content
+		draftPath, err = createEditableFile(statusUpdate, normalizedDate, refs)
+		if err != nil {
+			return fmt.Errorf("failed to create draft: %v", err)
+		}
 	}
 
-	// Get current status update
-	statusUpdate, err := getCurrentStatusUpdateForDate(normalizedDate)
-	if err != nil {
-		return fmt.Errorf("failed to fetch status update: %v", err)
+	saved, err := editDraftAndSave(ctx, normalizedDate, draftPath, statusUpdate, refs)
+	if errors.Is(err, errEditAborted) {
+		removeEditDraft(normalizedDate)
+		color.New(color.FgHiBlack).Println("⧗ edit aborted")
+		return nil
 	}
-
-	// Create temporary file with editable content
-	tempFile, err := createEditableFile(statusUpdate, normalizedDate)
 	if err != nil {
-		return fmt.Errorf("failed to create temporary file: %v", err)
+		reportKeptDraft(normalizedDate)
+		return err
 	}
-	defer os.Remove(tempFile.Name())
+	removeEditDraft(normalizedDate)
 
-	// Open editor
-	if err := openEditor(tempFile.Name()); err != nil {
-		return fmt.Errorf("failed to open editor: %v", err)
+	if saved {
+		color.New(color.FgGreen).Println("⧗ status update saved")
+	} else {
+		color.New(color.FgHiBlack).Println("⧗ no changes made")
 	}
+	return nil
+}
 
-	// Parse edited file
-	parsed, err := parseEditedFile(tempFile.Name())
+// editDraftAndSave opens a draft in the editor and saves the result, reporting
+// whether there was anything to save
+func editDraftAndSave(ctx context.Context, date, draftPath string, statusUpdate *StatusUpdate, refs map[string]string) (bool, error) {
+	// Open the editor until the draft parses, or the user empties it
+	parsed, err := editUntilValid(ctx, draftPath, refs)
 	if err != nil {
-		return fmt.Errorf("failed to parse edited file: %v", err)
+		return false, err
 	}
 
 	// Check if there were any changes
-	if !hasChanges(statusUpdate, parsed) {
-		color.New(color.FgHiBlack).Println("⧗ no changes made")
-		return nil
+	changes := diffItems(statusUpdate, parsed.Items)
+	if !hasChanges(statusUpdate, parsed, changes) {
+		return false, nil
 	}
 
-	// Send updates to API
-	if err := updateStatusUpdate(parsed, normalizedDate); err != nil {
-		return fmt.Errorf("failed to update status: %v", err)
+	// Send only the changed items to the API, merging with changes made meanwhile
+	if err := saveEditedStatusUpdate(ctx, date, statusUpdate, parsed, changes); err != nil {
+		return false, fmt.Errorf("failed to update status: %w", err)
 	}
-
-	color.New(color.FgGreen).Println("⧗ status update saved")
-	return nil
+	return true, nil
 }
 
-
-
 // getCurrentStatusUpdateForDate fetches the status update for a specific date
-func getCurrentStatusUpdateForDate(date string) (*StatusUpdate, error) {
+func getCurrentStatusUpdateForDate(ctx context.Context, date string) (*StatusUpdate, error) {
 	// Always use the by-date endpoint for consistency
-	return getStatusUpdateByDate(date)
+	return getStatusUpdateByDate(ctx, date)
 }
 
 
 
-// createEditableFile creates a temporary file with the current status items
-func createEditableFile(statusUpdate *StatusUpdate, date string) (*os.File, error) {
-	tempFile, err := os.CreateTemp("", "asyncstatus-edit-*.txt")
-	if err != nil {
-		return nil, err
-	}
-
+// createEditableFile creates the draft of a date with the current status items,
+// each followed by its reference, and returns its path
+func createEditableFile(statusUpdate *StatusUpdate, date string, refs map[string]string) (string, error) {
 	var content strings.Builder
 	
 	// Add header with instructions
 	targetDate := formatDateForDisplay(date)
 	if targetDate == "today" && statusUpdate != nil {
-		targetDate = statusUpdate.EffectiveFrom.Format("Monday, January 2, 2006")
+		targetDate = formatDate(statusUpdateDay(statusUpdate))
 	}
 	
 	content.WriteString(fmt.Sprintf("# Edit your status update for %s\n", targetDate))
@@ -161,15 +219,7 @@ func createEditableFile(statusUpdate *StatusUpdate, date string) (*os.File, erro
 	// Add existing items
 	if statusUpdate != nil && len(statusUpdate.Items) > 0 {
 		for _, item := range statusUpdate.Items {
-			var itemType string
-			if item.IsBlocker {
-				itemType = "blocker"
-			} else if item.IsInProgress {
-				itemType = "progress"
-			} else {
-				itemType = "done"
-			}
-			content.WriteString(fmt.Sprintf("%s %s\n", itemType, item.Content))
+			content.WriteString(formatItemLine(EditedItem{ID: item.ID, Type: item.Type(), Content: item.Content}, refs))
 		}
 	} else {
 		// Add example items for new status updates
@@ -180,27 +230,43 @@ func createEditableFile(statusUpdate *StatusUpdate, date string) (*os.File, erro
 
 	// Add mood and notes section
 	content.WriteString("\n")
-	if statusUpdate != nil && statusUpdate.Mood != nil && *statusUpdate.Mood != "" {
-		// Split multiline mood into separate mood lines
-		moodLines := strings.Split(*statusUpdate.Mood, "\n")
-		for _, line := range moodLines {
-			if strings.TrimSpace(line) != "" {
-				content.WriteString(fmt.Sprintf("mood %s\n", strings.TrimSpace(line)))
-			}
-		}
-	}
-	if statusUpdate != nil && statusUpdate.Notes != nil && *statusUpdate.Notes != "" {
-		// Split multiline notes into separate notes lines
-		notesLines := strings.Split(*statusUpdate.Notes, "\n")
-		for _, line := range notesLines {
-			if strings.TrimSpace(line) != "" {
-				content.WriteString(fmt.Sprintf("notes %s\n", strings.TrimSpace(line)))
-			}
-		}
+	if statusUpdate != nil {
+		content.WriteString(formatFieldLines("mood", singleLine(statusUpdate.Mood)))
+		content.WriteString(formatFieldLines("notes", statusUpdate.Notes))
 	}
 
 	// Add help section at the bottom
 	content.WriteString("\n")
+	writeEditHelp(&content)
+
+	return writeEditDraft(date, content.String(), statusUpdate, refs)
+}
+
+// formatItemLine returns the buffer line of an item, followed by its reference
+func formatItemLine(item EditedItem, refs map[string]string) string {
+	if ref, ok := refs[item.ID]; ok {
+		return fmt.Sprintf("%s %s  #%s\n", item.Type, item.Content, ref)
+	}
+	return fmt.Sprintf("%s %s\n", item.Type, item.Content)
+}
+
+// formatFieldLines returns the buffer lines of the mood or notes, one per line
+func formatFieldLines(field string, value *string) string {
+	if value == nil || *value == "" {
+		return ""
+	}
+	
+	var lines strings.Builder
+	for _, line := range strings.Split(*value, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines.WriteString(fmt.Sprintf("%s %s\n", field, strings.TrimSpace(line)))
+		}
+	}
+	return lines.String()
+}
+
+// writeEditHelp adds the help section to the bottom of an edit buffer
+func writeEditHelp(content *strings.Builder) {
 	content.WriteString("#\n")
 	content.WriteString("# Commands:\n")
 	content.WriteString("#   done <text>     = completed task\n")
@@ -212,6 +278,7 @@ func createEditableFile(statusUpdate *StatusUpdate, date string) (*os.File, erro
 	content.WriteString("#   notes <text>    = additional notes\n")
 	content.WriteString("#\n")
 	content.WriteString("# Lines starting with # are ignored\n")
+	content.WriteString("# The #code at the end of an item keeps its history, leave it in place\n")
 	content.WriteString("# You can reorder lines to change the order\n")
 	content.WriteString("# Delete lines to remove items\n")
 	content.WriteString("# Add new lines to add items\n")
@@ -222,19 +289,6 @@ func createEditableFile(statusUpdate *StatusUpdate, date string) (*os.File, erro
 	content.WriteString("#   blocker Waiting for API keys\n")
 	content.WriteString("#   mood productive\n")
 	content.WriteString("#   notes Great progress today, team collaboration was excellent\n")
-
-	if _, err := tempFile.WriteString(content.String()); err != nil {
-		tempFile.Close()
-		os.Remove(tempFile.Name())
-		return nil, err
-	}
-
-	if err := tempFile.Close(); err != nil {
-		os.Remove(tempFile.Name())
-		return nil, err
-	}
-
-	return tempFile, nil
 }
 
 // openEditor opens the user's preferred editor
@@ -264,8 +318,13 @@ func openEditor(filename string) error {
 
 // getEditor returns the user's preferred editor
 func getEditor() string {
+	// The editor setting covers ASYNCSTATUS_EDITOR and the config file
+	if editor := getSetting("editor"); editor != "" {
+		return editor
+	}
+	
 	// Check environment variables in order of preference
-	for _, env := range []string{"ASYNCSTATUS_EDITOR", "GIT_EDITOR", "VISUAL", "EDITOR"} {
+	for _, env := range []string{"GIT_EDITOR", "VISUAL", "EDITOR"} {
 		if editor := os.Getenv(env); editor != "" {
 			return editor
 		}
@@ -328,216 +387,206 @@ func getGitVarEditor() string {
 
 // ParsedStatusUpdate represents the parsed content from the editor
 type ParsedStatusUpdate struct {
-	Items []EditStatusUpdateItem
+	Items []EditedItem
 	Mood  *string
 	Notes *string
 }
 
-// parseEditedFile parses the edited file and returns the status items with mood and notes
-func parseEditedFile(filename string) (*ParsedStatusUpdate, error) {
+// parseEditedFile parses the edited file and returns the status items with mood
+// and notes, resolving item references through refs. Invalid lines are
+// reported together as *editProblems.
+func parseEditedFile(filename string, refs map[string]string) (*ParsedStatusUpdate, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
-	result := &ParsedStatusUpdate{
-		Items: []EditStatusUpdateItem{},
-	}
 	scanner := bufio.NewScanner(file)
-	order := 1
-
-	// Regex to parse different line types
-	itemRegex := regexp.MustCompile(`^\s*(done|progress|blocker)\s+(.+)$`)
-	moodRegex := regexp.MustCompile(`^\s*mood\s+(.+)$`)
-	notesRegex := regexp.MustCompile(`^\s*notes\s+(.+)$`)
+	parser := newEditLineParser(refs, make(map[string]bool))
+	lineNumber := 0
 
 	for scanner.Scan() {
+		lineNumber++
 		line := strings.TrimSpace(scanner.Text())
 		
 		// Skip empty lines and comments
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
+		parser.parseLine(lineNumber, line)
+	}
 
-		// Try to match item types (done, progress, blocker)
-		if matches := itemRegex.FindStringSubmatch(line); len(matches) == 3 {
-			itemType := matches[1]
-			content := strings.TrimSpace(matches[2])
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading file: %v", err)
+	}
+	if len(parser.problems) > 0 {
+		return nil, &editProblems{problems: parser.problems}
+	}
 
-			if content == "" {
-				return nil, fmt.Errorf("empty content for item: %s", line)
-			}
+	return parser.result, nil
+}
 
-			result.Items = append(result.Items, EditStatusUpdateItem{
-				Content: content,
-				Type:    itemType,
-				Order:   order,
-			})
-			order++
-			continue
-		}
+// Regex to parse different line types
+var (
+	itemLineRegex  = regexp.MustCompile(`^(done|progress|blocker)(?:\s+(.*))?$`)
+	moodLineRegex  = regexp.MustCompile(`^mood(?:\s+(.*))?$`)
+	notesLineRegex = regexp.MustCompile(`^notes(?:\s+(.*))?$`)
+)
 
-		// Try to match mood
-		if matches := moodRegex.FindStringSubmatch(line); len(matches) == 2 {
-			mood := strings.TrimSpace(matches[1])
-			if mood != "" {
-				if result.Mood == nil {
-					result.Mood = &mood
-				} else {
-					combined := *result.Mood + "\n" + mood
-					result.Mood = &combined
-				}
-			}
-			continue
+// editLineParser turns the lines of an edit buffer into a status update.
+// Every problem is collected, so they can all be fixed in one go.
+type editLineParser struct {
+	result   *ParsedStatusUpdate
+	itemIDs  map[string]string
+	usedIDs  map[string]bool
+	moodLine int
+	problems []editProblem
+}
+
+// newEditLineParser creates a parser resolving item references through refs.
+// Parsers sharing usedIDs use each reference once between them.
+func newEditLineParser(refs map[string]string, usedIDs map[string]bool) *editLineParser {
+	itemIDs := make(map[string]string, len(refs))
+	for id, ref := range refs {
+		itemIDs[ref] = id
+	}
+	return &editLineParser{
+		result:  &ParsedStatusUpdate{Items: []EditedItem{}},
+		itemIDs: itemIDs,
+		usedIDs: usedIDs,
+	}
+}
+
+// parseLine parses a line that isn't blank or a comment
+func (p *editLineParser) parseLine(lineNumber int, line string) {
+	// Try to match item types (done, progress, blocker)
+	if matches := itemLineRegex.FindStringSubmatch(line); matches != nil {
+		itemType := matches[1]
+		content := strings.TrimSpace(matches[2])
+
+		if content == "" {
+			p.problems = append(p.problems, editProblem{line: lineNumber, message: fmt.Sprintf("%s needs a description", itemType)})
+			return
 		}
 
-		// Try to match notes
-		if matches := notesRegex.FindStringSubmatch(line); len(matches) == 2 {
-			notes := strings.TrimSpace(matches[1])
-			if notes != "" {
-				if result.Notes == nil {
-					result.Notes = &notes
-				} else {
-					combined := *result.Notes + "\n" + notes
-					result.Notes = &combined
-				}
-			}
-			continue
+		content, id := splitItemRef(content, p.itemIDs, p.usedIDs)
+		if length := utf8.RuneCountInString(content); length > maxItemLength {
+			p.problems = append(p.problems, editProblem{line: lineNumber, message: fmt.Sprintf("item is %d characters long, the limit is %d", length, maxItemLength)})
+			return
 		}
+		p.result.Items = append(p.result.Items, EditedItem{
+			ID:      id,
+			Type:    client.ItemType(itemType),
+			Content: content,
+		})
+		return
+	}
+
+	// Try to match mood, which is a single line
+	if matches := moodLineRegex.FindStringSubmatch(line); matches != nil {
+		mood := strings.TrimSpace(matches[1])
+		switch {
+		case mood == "":
+			p.problems = append(p.problems, editProblem{line: lineNumber, message: "mood needs text, delete the line to clear it"})
+		case p.moodLine > 0:
+			p.problems = append(p.problems, editProblem{line: lineNumber, message: "duplicate mood, keep one", seeLine: p.moodLine})
+		default:
+			p.result.Mood = &mood
+			p.moodLine = lineNumber
+		}
+		return
+	}
 
-		// If no patterns match, return an error
-		return nil, fmt.Errorf("invalid line format: %s\nExpected format: 'done|progress|blocker <description>', 'mood <mood>', or 'notes <text>'", line)
+	// Try to match notes, whose lines are joined
+	if matches := notesLineRegex.FindStringSubmatch(line); matches != nil {
+		notes := strings.TrimSpace(matches[1])
+		if notes == "" {
+			p.problems = append(p.problems, editProblem{line: lineNumber, message: "notes needs text, delete the line to clear it"})
+		} else if p.result.Notes == nil {
+			p.result.Notes = &notes
+		} else {
+			combined := *p.result.Notes + "\n" + notes
+			p.result.Notes = &combined
+		}
+		return
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file: %v", err)
+	// Conflict markers must be resolved before saving
+	if conflictMarkerRegex.MatchString(line) {
+		p.problems = append(p.problems, editProblem{line: lineNumber, message: "unresolved conflict, keep the lines you want and delete the marker lines"})
+		return
 	}
 
-	return result, nil
+	// Anything else is a problem
+	p.problems = append(p.problems, editProblem{line: lineNumber, message: "unknown line, start it with done, progress, blocker, mood or notes"})
 }
 
 // hasChanges checks if the edited content differs from the original
-func hasChanges(statusUpdate *StatusUpdate, parsed *ParsedStatusUpdate) bool {
-	// Check if there are any items or special fields to save
-	hasContent := len(parsed.Items) > 0 || parsed.Mood != nil || parsed.Notes != nil
-	
-	if statusUpdate == nil || len(statusUpdate.Items) == 0 {
-		// Also check if mood or notes are different from nil/empty
-		if statusUpdate != nil {
-			originalMood := ""
-			if statusUpdate.Mood != nil {
-				originalMood = *statusUpdate.Mood
-			}
-			originalNotes := ""
-			if statusUpdate.Notes != nil {
-				originalNotes = *statusUpdate.Notes
-			}
-			
-			newMood := ""
-			if parsed.Mood != nil {
-				newMood = *parsed.Mood
-			}
-			newNotes := ""
-			if parsed.Notes != nil {
-				newNotes = *parsed.Notes
-			}
-			
-			if originalMood != newMood || originalNotes != newNotes {
-				return true
-			}
-		}
-		return hasContent
-	}
-
-	// Check if item count changed
-	if len(statusUpdate.Items) != len(parsed.Items) {
+func hasChanges(statusUpdate *StatusUpdate, parsed *ParsedStatusUpdate, changes []client.ItemChange) bool {
+	if len(changes) > 0 {
 		return true
 	}
 
-	// Check if any items changed
-	for i, item := range statusUpdate.Items {
-		if i >= len(parsed.Items) {
-			return true
-		}
-
-		editedItem := parsed.Items[i]
-		
-		var originalType string
-		if item.IsBlocker {
-			originalType = "blocker"
-		} else if item.IsInProgress {
-			originalType = "progress"
-		} else {
-			originalType = "done"
+	// Check if mood or notes changed, the mood as the buffer shows it
+	originalMood := ""
+	originalNotes := ""
+	if statusUpdate != nil {
+		if statusUpdate.Mood != nil {
+			originalMood = *singleLine(statusUpdate.Mood)
 		}
-
-		if originalType != editedItem.Type || item.Content != editedItem.Content {
-			return true
+		if statusUpdate.Notes != nil {
+			originalNotes = *statusUpdate.Notes
 		}
 	}
-
-	// Check if mood changed
-	originalMood := ""
-	if statusUpdate.Mood != nil {
-		originalMood = *statusUpdate.Mood
-	}
 	newMood := ""
 	if parsed.Mood != nil {
 		newMood = *parsed.Mood
 	}
-	if originalMood != newMood {
-		return true
-	}
-
-	// Check if notes changed
-	originalNotes := ""
-	if statusUpdate.Notes != nil {
-		originalNotes = *statusUpdate.Notes
-	}
 	newNotes := ""
 	if parsed.Notes != nil {
 		newNotes = *parsed.Notes
 	}
-	if originalNotes != newNotes {
-		return true
-	}
 
-	return false
+	return originalMood != newMood || originalNotes != newNotes
 }
 
-// updateStatusUpdate sends the edited content to the API
-func updateStatusUpdate(parsed *ParsedStatusUpdate, date string) error {
-	payload := EditStatusUpdateRequest{
-		Items: parsed.Items,
-		Date:  date,
-		Mood:  parsed.Mood,
-		Notes: parsed.Notes,
+// updateStatusUpdate sends the item changes, and the mood and notes if they
+// differ from base, to the API.
+// It fails with client.ErrConflict if the status update no longer matches base.
+func updateStatusUpdate(ctx context.Context, parsed *ParsedStatusUpdate, changes []client.ItemChange, date string, base *StatusUpdate) error {
+	// A mood or notes edit has no item changes, sent as an empty list
+	if changes == nil {
+		changes = []client.ItemChange{}
 	}
-
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to prepare request: %v", err)
+	payload := &client.PatchStatusUpdateRequest{
+		Changes:           changes,
+		Date:              date,
+		ExpectedUpdatedAt: &client.Precondition{},
+	}
+	var baseMood, baseNotes *string
+	if base != nil {
+		payload.ExpectedUpdatedAt.UpdatedAt = &base.UpdatedAt
+		baseMood, baseNotes = base.Mood, base.Notes
+	}
+	// Only what changed is sent, so a mood kept over several lines stays so
+	if derefString(singleLine(baseMood)) != derefString(singleLine(parsed.Mood)) {
+		payload.Mood = &client.NullableString{Value: parsed.Mood}
+	}
+	if derefString(baseNotes) != derefString(parsed.Notes) {
+		payload.Notes = &client.NullableString{Value: parsed.Notes}
 	}
 
-	endpoint := "/cli/status-updates/edit"
-	client, req, err := makeAuthenticatedJSONRequest("PUT", endpoint)
+	apiClient, err := newAPIClient(ctx)
 	if err != nil {
 		return err
 	}
 
-	req.Body = io.NopCloser(bytes.NewBuffer(jsonData))
-
-	resp, err := client.Do(req)
+	statusUpdate, err := apiClient.PatchStatusUpdate(ctx, payload)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("server error (status %d): %s", resp.StatusCode, string(body))
+		return err
 	}
+	writeStatusUpdateCache(date, statusUpdate)
 
 	return nil
 }
\ No newline at end of file
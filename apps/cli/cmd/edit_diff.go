@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+
+	"asyncstatus.com/cli/client"
+)
+
+// minItemRefLength is the length of item references in the edit buffer,
+// grown as needed to keep them unique within a status update
+const minItemRefLength = 4
+
+// itemRefRegex matches the item reference at the end of an item line
+var itemRefRegex = regexp.MustCompile(`^(.*\S)\s+#([0-9a-f]+)$`)
+
+// EditedItem is an item line of the edit buffer. ID is empty for new items.
+type EditedItem struct {
+	ID      string
+	Type    client.ItemType
+	Content string
+}
+
+// itemRefs returns a short reference for each item ID, derived from a hash of
+// the ID so the same item keeps its reference between edits
+func itemRefs(items []StatusUpdateItem) map[string]string {
+	hashes := make(map[string]string, len(items))
+	for _, item := range items {
+		sum := sha256.Sum256([]byte(item.ID))
+		hashes[item.ID] = hex.EncodeToString(sum[:])
+	}
+
+	for length := minItemRefLength; ; length++ {
+		refs := make(map[string]string, len(hashes))
+		seen := make(map[string]bool, len(hashes))
+		unique := true
+		for id, hash := range hashes {
+			ref := hash[:length]
+			if seen[ref] {
+				unique = false
+				break
+			}
+			seen[ref] = true
+			refs[id] = ref
+		}
+		if unique || length == sha256.Size*2 {
+			return refs
+		}
+	}
+}
+
+// splitItemRef separates a trailing item reference from the content of an item
+// line. Only references of the buffer's items count, so content that happens to
+// end in something like #1234 is kept as is. Each reference is used once; a
+// copied line becomes a new item.
+func splitItemRef(content string, itemIDs map[string]string, used map[string]bool) (string, string) {
+	matches := itemRefRegex.FindStringSubmatch(content)
+	if matches == nil {
+		return content, ""
+	}
+	id, ok := itemIDs[matches[2]]
+	if !ok || used[id] {
+		return content, ""
+	}
+	used[id] = true
+	return matches[1], id
+}
+
+// diffItems returns the changes that turn the items of a status update into
+// the edited items: deletes, then updates of changed fields, then inserts
+func diffItems(statusUpdate *StatusUpdate, items []EditedItem) []client.ItemChange {
+	original := make(map[string]StatusUpdateItem)
+	if statusUpdate != nil {
+		for _, item := range statusUpdate.Items {
+			original[item.ID] = item
+		}
+	}
+
+	kept := make(map[string]bool, len(items))
+	var updates, inserts []client.ItemChange
+	for i, item := range items {
+		order := i + 1
+		existing, ok := original[item.ID]
+		if item.ID == "" || !ok {
+			inserts = append(inserts, client.ItemChange{Op: client.ItemChangeInsert, Content: item.Content, Type: item.Type, Order: order})
+			continue
+		}
+		kept[item.ID] = true
+
+		change := client.ItemChange{Op: client.ItemChangeUpdate, ID: item.ID}
+		if existing.Content != item.Content {
+			change.Content = item.Content
+		}
+		if existing.Type() != item.Type {
+			change.Type = item.Type
+		}
+		if existing.Order != order {
+			change.Order = order
+		}
+		if change.Content != "" || change.Type != "" || change.Order != 0 {
+			updates = append(updates, change)
+		}
+	}
+
+	var changes []client.ItemChange
+	if statusUpdate != nil {
+		for _, item := range statusUpdate.Items {
+			if !kept[item.ID] {
+				changes = append(changes, client.ItemChange{Op: client.ItemChangeDelete, ID: item.ID})
+			}
+		}
+	}
+	changes = append(changes, updates...)
+	return append(changes, inserts...)
+}
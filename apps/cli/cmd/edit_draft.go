@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// editDraft records what an edit buffer was written against, so a resumed
+// edit can merge with changes made since. A range edit has a base per day,
+// null for days without an update, and the range as its date.
+type editDraft struct {
+	Date    string                   `json:"date"`
+	SavedAt time.Time                `json:"savedAt"`
+	Base    *StatusUpdate            `json:"base"`
+	Bases   map[string]*StatusUpdate `json:"bases,omitempty"`
+	Refs    map[string]string        `json:"refs"`
+}
+
+// getDraftsDir returns the directory holding edit buffers that weren't saved
+// to the server yet, kept apart per organization like the cache
+func getDraftsDir() string {
+	if org := getActiveOrganization(); org != "" {
+		return filepath.Join(getActiveProfileStateDir(), "drafts", "orgs", org)
+	}
+	return filepath.Join(getActiveProfileStateDir(), "drafts")
+}
+
+// getDraftPath returns the path of the edit buffer of a YYYY-MM-DD date
+func getDraftPath(date string) string {
+	return filepath.Join(getDraftsDir(), date+".txt")
+}
+
+// writeEditDraft stores an edit buffer with the status update and item
+// references it was written against, returning the path to open in the editor
+func writeEditDraft(date, content string, base *StatusUpdate, refs map[string]string) (string, error) {
+	return writeDraft(date, content, editDraft{Date: date, SavedAt: time.Now(), Base: base, Refs: refs})
+}
+
+// writeRangeEditDraft stores the edit buffer of a range with the status
+// update of each day, keyed by the range
+func writeRangeEditDraft(r dateRange, content string, bases map[string]*StatusUpdate, refs map[string]string) (string, error) {
+	return writeDraft(r.String(), content, editDraft{Date: r.String(), SavedAt: time.Now(), Bases: bases, Refs: refs})
+}
+
+// writeDraft stores an edit buffer and what it was written against
+func writeDraft(date, content string, draft editDraft) (string, error) {
+	if err := os.MkdirAll(getDraftsDir(), 0700); err != nil {
+		return "", fmt.Errorf("failed to create drafts directory: %v", err)
+	}
+
+	jsonData, err := json.MarshalIndent(draft, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal draft: %v", err)
+	}
+	if err := writeFileAtomic(filepath.Join(getDraftsDir(), date+".json"), jsonData, 0600); err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(getDraftPath(date), []byte(content), 0600); err != nil {
+		return "", err
+	}
+
+	return getDraftPath(date), nil
+}
+
+// loadEditDraft returns what the draft of a date was written against, and
+// false if there is no draft
+func loadEditDraft(date string) (*editDraft, bool, error) {
+	if _, err := os.Stat(getDraftPath(date)); errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+
+	content, err := os.ReadFile(filepath.Join(getDraftsDir(), date+".json"))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read draft for %s: %v", date, err)
+	}
+	var draft editDraft
+	if err := json.Unmarshal(content, &draft); err != nil {
+		return nil, false, fmt.Errorf("failed to parse draft for %s: %v", date, err)
+	}
+	return &draft, true, nil
+}
+
+// removeEditDraft deletes the draft of a date once the server has the edit
+func removeEditDraft(date string) {
+	_ = os.Remove(getDraftPath(date))
+	_ = os.Remove(filepath.Join(getDraftsDir(), date+".json"))
+}
+
+// listEditDrafts returns the dates that have a draft, oldest first
+func listEditDrafts() []string {
+	entries, err := os.ReadDir(getDraftsDir())
+	if err != nil {
+		return nil
+	}
+
+	var dates []string
+	for _, entry := range entries {
+		if date, ok := strings.CutSuffix(entry.Name(), ".txt"); ok && !entry.IsDir() {
+			dates = append(dates, date)
+		}
+	}
+	sort.Strings(dates)
+	return dates
+}
+
+// getResumeDate picks the draft to resume: the given date or range, or the
+// only draft
+func getResumeDate(date string) (string, error) {
+	if strings.Contains(date, "..") {
+		if r, err := parseDateRange(date, maxEditRangeDays); err == nil {
+			return r.String(), nil
+		}
+	}
+	if date != "" {
+		return parseDate(date)
+	}
+
+	dates := listEditDrafts()
+	switch len(dates) {
+	case 0:
+		return "", validationError("no edit drafts to resume")
+	case 1:
+		return dates[0], nil
+	}
+	return "", &cliError{
+		class:   errorClassValidation,
+		message: fmt.Sprintf("several edit drafts: %s", strings.Join(dates, ", ")),
+		hint:    fmt.Sprintf("pick one, e.g. asyncstatus edit --resume %s", dates[len(dates)-1]),
+	}
+}
+
+// reportKeptDraft tells where an edit that wasn't saved is kept
+func reportKeptDraft(date string) {
+	fmt.Fprintln(os.Stderr, color.New(color.FgYellow).Sprintf("⧗ your edit is kept in %s", getDraftPath(date)))
+	fmt.Fprintln(os.Stderr, color.New(color.FgHiBlack).Sprint("  run: ")+color.New(color.FgWhite).Sprintf("asyncstatus edit --resume %s", date)+color.New(color.FgHiBlack).Sprint(" to continue"))
+}
@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"asyncstatus.com/cli/client"
+	"github.com/fatih/color"
+)
+
+// maxEditSaveAttempts bounds how often edit merges and retries when the
+// status update keeps changing while it saves
+const maxEditSaveAttempts = 3
+
+// conflictMarkerRegex matches the marker lines of a conflict in the edit buffer
+var conflictMarkerRegex = regexp.MustCompile(`^(<{7}|={7}|>{7})(\s|$)`)
+
+// mergedItem is an item of a three-way merge, either resolved or a conflict
+// between your version and theirs. A nil side means that side deleted it.
+type mergedItem struct {
+	item     EditedItem
+	conflict bool
+	ours     *EditedItem
+	theirs   *EditedItem
+}
+
+// mergedText is the mood or notes after a three-way merge
+type mergedText struct {
+	value    string
+	conflict bool
+	ours     string
+	theirs   string
+}
+
+// statusUpdateMerge is the result of merging your edit with changes made
+// elsewhere since the status update was fetched
+type statusUpdateMerge struct {
+	items []mergedItem
+	mood  mergedText
+	notes mergedText
+}
+
+// saveEditedStatusUpdate saves an edit made against base. When the status
+// update changed in the meantime, it is fetched again and the edit merged with
+// it: clean merges are saved right away, conflicts reopen the editor.
+func saveEditedStatusUpdate(ctx context.Context, date string, base *StatusUpdate, parsed *ParsedStatusUpdate, changes []client.ItemChange) error {
+	for attempt := 1; ; attempt++ {
+		err := updateStatusUpdate(ctx, parsed, changes, date, base)
+		if err == nil || !errors.Is(err, client.ErrConflict) || attempt == maxEditSaveAttempts {
+			return err
+		}
+
+		remote, err := getStatusUpdateByDate(ctx, date)
+		if err != nil {
+			return fmt.Errorf("failed to fetch changes made elsewhere: %w", err)
+		}
+
+		merge := mergeStatusUpdates(base, parsed, remote)
+		if merge.conflicted() {
+			color.New(color.FgYellow).Println("⧗ changed elsewhere while you were editing, resolve the conflicts in the editor")
+			if parsed, err = resolveConflictsInEditor(ctx, merge, base, remote, date); err != nil {
+				return err
+			}
+		} else {
+			color.New(color.FgHiBlack).Println("⧗ changed elsewhere while you were editing, merged")
+			parsed = merge.resolved()
+		}
+
+		base = remote
+		changes = diffItems(base, parsed.Items)
+		if !hasChanges(base, parsed, changes) {
+			return nil
+		}
+	}
+}
+
+// merge3 merges one value changed on two sides, reporting false when both
+// changed it differently
+func merge3(base, ours, theirs string) (string, bool) {
+	switch {
+	case ours == theirs:
+		return ours, true
+	case ours == base:
+		return theirs, true
+	case theirs == base:
+		return ours, true
+	}
+	return "", false
+}
+
+// mergeText merges the mood or notes
+func mergeText(base, ours, theirs *string) mergedText {
+	value, ok := merge3(derefString(base), derefString(ours), derefString(theirs))
+	if !ok {
+		return mergedText{conflict: true, ours: derefString(ours), theirs: derefString(theirs)}
+	}
+	return mergedText{value: value}
+}
+
+// editedItems returns the items of a status update as edit buffer items
+func editedItems(statusUpdate *StatusUpdate) []EditedItem {
+	if statusUpdate == nil {
+		return nil
+	}
+	items := make([]EditedItem, 0, len(statusUpdate.Items))
+	for _, item := range statusUpdate.Items {
+		items = append(items, EditedItem{ID: item.ID, Type: item.Type(), Content: item.Content})
+	}
+	return items
+}
+
+// mergeStatusUpdates merges your edit of base with the remote version. Items
+// are matched by ID and merged field by field; the result follows your order,
+// with items added elsewhere placed after the item they follow remotely.
+func mergeStatusUpdates(base *StatusUpdate, ours *ParsedStatusUpdate, remote *StatusUpdate) *statusUpdateMerge {
+	baseItems := make(map[string]EditedItem)
+	for _, item := range editedItems(base) {
+		baseItems[item.ID] = item
+	}
+	remoteItems := make(map[string]EditedItem)
+	for _, item := range editedItems(remote) {
+		remoteItems[item.ID] = item
+	}
+
+	merge := &statusUpdateMerge{}
+	kept := make(map[string]bool)
+	for _, item := range ours.Items {
+		baseItem, inBase := baseItems[item.ID]
+		if item.ID == "" || !inBase {
+			merge.items = append(merge.items, mergedItem{item: item})
+			continue
+		}
+		kept[item.ID] = true
+
+		remoteItem, inRemote := remoteItems[item.ID]
+		if !inRemote {
+			// Deleted elsewhere: gone unless you changed it
+			if item != baseItem {
+				merge.items = append(merge.items, mergedItem{conflict: true, ours: &item})
+			}
+			continue
+		}
+
+		content, contentOK := merge3(baseItem.Content, item.Content, remoteItem.Content)
+		itemType, typeOK := merge3(string(baseItem.Type), string(item.Type), string(remoteItem.Type))
+		if !contentOK || !typeOK {
+			merge.items = append(merge.items, mergedItem{conflict: true, ours: &item, theirs: &remoteItem})
+			continue
+		}
+		merge.items = append(merge.items, mergedItem{item: EditedItem{ID: item.ID, Type: client.ItemType(itemType), Content: content}})
+	}
+
+	// Place items added elsewhere, and items you deleted but were changed
+	// elsewhere, after the item they follow in the remote version
+	anchor := -1
+	for _, remoteItem := range editedItems(remote) {
+		if i := merge.indexOf(remoteItem.ID); i >= 0 {
+			anchor = i
+			continue
+		}
+
+		var placed mergedItem
+		baseItem, inBase := baseItems[remoteItem.ID]
+		switch {
+		case !inBase:
+			placed = mergedItem{item: remoteItem}
+		case !kept[remoteItem.ID] && remoteItem != baseItem:
+			placed = mergedItem{conflict: true, theirs: &remoteItem}
+		default:
+			continue
+		}
+		anchor++
+		merge.items = append(merge.items[:anchor], append([]mergedItem{placed}, merge.items[anchor:]...)...)
+	}
+
+	var baseMood, baseNotes, remoteMood, remoteNotes *string
+	if base != nil {
+		baseMood, baseNotes = base.Mood, base.Notes
+	}
+	if remote != nil {
+		remoteMood, remoteNotes = remote.Mood, remote.Notes
+	}
+	merge.mood = mergeText(singleLine(baseMood), ours.Mood, singleLine(remoteMood))
+	merge.notes = mergeText(baseNotes, ours.Notes, remoteNotes)
+
+	return merge
+}
+
+// indexOf returns the position of the item with an ID, or -1
+func (m *statusUpdateMerge) indexOf(id string) int {
+	for i, item := range m.items {
+		switch {
+		case item.conflict && item.ours != nil && item.ours.ID == id,
+			item.conflict && item.theirs != nil && item.theirs.ID == id,
+			!item.conflict && item.item.ID != "" && item.item.ID == id:
+			return i
+		}
+	}
+	return -1
+}
+
+// conflicted reports whether any item, the mood or the notes conflict
+func (m *statusUpdateMerge) conflicted() bool {
+	if m.mood.conflict || m.notes.conflict {
+		return true
+	}
+	for _, item := range m.items {
+		if item.conflict {
+			return true
+		}
+	}
+	return false
+}
+
+// resolved returns the merged edit of a merge without conflicts
+func (m *statusUpdateMerge) resolved() *ParsedStatusUpdate {
+	parsed := &ParsedStatusUpdate{Items: []EditedItem{}}
+	for _, item := range m.items {
+		parsed.Items = append(parsed.Items, item.item)
+	}
+	if m.mood.value != "" {
+		parsed.Mood = &m.mood.value
+	}
+	if m.notes.value != "" {
+		parsed.Notes = &m.notes.value
+	}
+	return parsed
+}
+
+// writeConflict adds a git-style conflict between your lines and theirs
+func writeConflict(content *strings.Builder, ours, theirs string) {
+	content.WriteString("<<<<<<< yours\n")
+	content.WriteString(ours)
+	content.WriteString("=======\n")
+	content.WriteString(theirs)
+	content.WriteString(">>>>>>> theirs (changed elsewhere)\n")
+}
+
+// resolveConflictsInEditor opens the merge with conflict markers in the editor
+// and returns the resolved edit
+func resolveConflictsInEditor(ctx context.Context, merge *statusUpdateMerge, base, remote *StatusUpdate, date string) (*ParsedStatusUpdate, error) {
+	// References cover the remote items and those only you still have
+	var items []StatusUpdateItem
+	if remote != nil {
+		items = append(items, remote.Items...)
+	}
+	if base != nil {
+		for _, item := range base.Items {
+			if remote == nil || !containsItem(remote.Items, item.ID) {
+				items = append(items, item)
+			}
+		}
+	}
+	refs := itemRefs(items)
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("# Edit your status update for %s\n", formatDateForDisplay(date)))
+	content.WriteString("#\n")
+	content.WriteString("# It was changed elsewhere while you were editing. Changes that don't\n")
+	content.WriteString("# overlap are merged; for each conflict below keep the lines you want\n")
+	content.WriteString("# and delete the others with the <<<<<<<, ======= and >>>>>>> markers.\n")
+	content.WriteString("\n")
+
+	for _, item := range merge.items {
+		if !item.conflict {
+			content.WriteString(formatItemLine(item.item, refs))
+			continue
+		}
+		var ours, theirs string
+		if item.ours != nil {
+			ours = formatItemLine(*item.ours, refs)
+		}
+		if item.theirs != nil {
+			theirs = formatItemLine(*item.theirs, refs)
+		}
+		writeConflict(&content, ours, theirs)
+	}
+
+	content.WriteString("\n")
+	for _, field := range []struct {
+		name string
+		text mergedText
+	}{{"mood", merge.mood}, {"notes", merge.notes}} {
+		if field.text.conflict {
+			writeConflict(&content, formatFieldLines(field.name, &field.text.ours), formatFieldLines(field.name, &field.text.theirs))
+		} else {
+			content.WriteString(formatFieldLines(field.name, &field.text.value))
+		}
+	}
+
+	content.WriteString("\n")
+	writeEditHelp(&content)
+
+	// The draft now holds the merge, to be saved against the remote version
+	draftPath, err := writeEditDraft(date, content.String(), remote, refs)
+	if err != nil {
+		return nil, err
+	}
+	return editUntilValid(ctx, draftPath, refs)
+}
+
+// containsItem reports whether items include one with an ID
+func containsItem(items []StatusUpdateItem, id string) bool {
+	for _, item := range items {
+		if item.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// clearSectionLine empties a day in a range edit, where an empty section
+// leaves the day alone
+const clearSectionLine = "clear"
+
+// sectionHeadingRegex matches the heading that starts a day in a range edit
+var sectionHeadingRegex = regexp.MustCompile(`^##\s+(.*)$`)
+
+// rangeEditSection is a day of a range edit that has lines or was cleared
+type rangeEditSection struct {
+	date   string
+	parsed *ParsedStatusUpdate
+}
+
+// formatSectionHeading returns the heading of a YYYY-MM-DD day in a range edit
+func formatSectionHeading(date string) string {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "## " + date
+	}
+	return "## " + day.Format("Monday, January 2")
+}
+
+// handleEditRange edits the status updates of every day of a range in one
+// buffer, with a section per day
+func handleEditRange(ctx context.Context, expr string, resume bool) error {
+	// The editor needs a terminal, so fail before fetching anything
+	if !stdinIsTerminal() {
+		return validationError("stdin is not a terminal, can't open an editor")
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	r, err := parseDateRange(expr, maxEditRangeDays)
+	if err != nil {
+		return err
+	}
+	key := r.String()
+
+	if resume {
+		draft, ok, err := loadEditDraft(key)
+		if err != nil {
+			return err
+		}
+		if !ok || draft.Bases == nil {
+			return validationError("no edit draft for %s", describeDateRange(r))
+		}
+		return editRangeDraftAndSave(ctx, key, draft.Bases, draft.Refs)
+	}
+
+	// Starting over would overwrite the draft, and saving a day clears its own
+	// draft, so those are resumed first
+	for _, date := range append([]string{key}, r.dates()...) {
+		if _, ok, _ := loadEditDraft(date); ok {
+			return &cliError{
+				class:   errorClassValidation,
+				message: fmt.Sprintf("an unsaved edit is kept in %s", getDraftPath(date)),
+				hint:    fmt.Sprintf("run: asyncstatus edit --resume %s, or delete the draft to start over", date),
+			}
+		}
+	}
+
+	// Get the status update of each day, with references unique in the buffer
+	bases := make(map[string]*StatusUpdate, r.days())
+	var items []StatusUpdateItem
+	for _, date := range r.dates() {
+		statusUpdate, err := getCurrentStatusUpdateForDate(ctx, date)
+		if err != nil {
+			return fmt.Errorf("failed to fetch status update for %s: %w", formatDateForDisplay(date), err)
+		}
+		bases[date] = statusUpdate
+		if statusUpdate != nil {
+			items = append(items, statusUpdate.Items...)
+		}
+	}
+	refs := itemRefs(items)
+
+	if _, err := createRangeEditableFile(r, bases, refs); err != nil {
+		return fmt.Errorf("failed to create draft: %v", err)
+	}
+	return editRangeDraftAndSave(ctx, key, bases, refs)
+}
+
+// editRangeDraftAndSave opens the draft of a range in the editor and saves the
+// days that changed, reporting each
+func editRangeDraftAndSave(ctx context.Context, key string, bases map[string]*StatusUpdate, refs map[string]string) error {
+	draftPath := getDraftPath(key)
+	var sections []rangeEditSection
+	err := reopenUntilValid(ctx, draftPath, func() (bool, error) {
+		var err error
+		sections, err = parseRangeEditedFile(draftPath, bases, refs)
+		return len(sections) == 0, err
+	})
+	if errors.Is(err, errEditAborted) {
+		removeEditDraft(key)
+		color.New(color.FgHiBlack).Println("⧗ edit aborted")
+		return nil
+	}
+	if err != nil {
+		reportKeptDraft(key)
+		return err
+	}
+
+	// Days are saved one by one, each merging with changes made meanwhile
+	var saved []string
+	for _, section := range sections {
+		base := bases[section.date]
+		changes := diffItems(base, section.parsed.Items)
+		if !hasChanges(base, section.parsed, changes) {
+			continue
+		}
+		if err := saveEditedStatusUpdate(ctx, section.date, base, section.parsed, changes); err != nil {
+			reportRangeSaved(saved, len(bases))
+			reportKeptDraft(key)
+			return fmt.Errorf("failed to update status for %s: %w", formatDateForDisplay(section.date), err)
+		}
+		// A conflict on the day may have left a draft of its own
+		removeEditDraft(section.date)
+		saved = append(saved, section.date)
+	}
+	removeEditDraft(key)
+
+	if len(saved) == 0 {
+		color.New(color.FgHiBlack).Println("⧗ no changes made")
+		return nil
+	}
+	reportRangeSaved(saved, len(bases))
+	return nil
+}
+
+// reportRangeSaved lists the days of a range edit that were saved
+func reportRangeSaved(saved []string, days int) {
+	if len(saved) == 0 {
+		return
+	}
+	color.New(color.FgGreen).Printf("⧗ saved %d of %d days\n", len(saved), days)
+	for _, date := range saved {
+		fmt.Println(color.New(color.FgGreen).Sprint("  ✓ ") + formatDateForDisplay(date))
+	}
+}
+
+// createRangeEditableFile creates the draft of a range with a section per day,
+// and returns its path
+func createRangeEditableFile(r dateRange, bases map[string]*StatusUpdate, refs map[string]string) (string, error) {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("# Edit your status updates from %s\n", describeDateRange(r)))
+	content.WriteString("#\n")
+	content.WriteString("# Each day has a section below. A section left empty leaves the day\n")
+	content.WriteString("# unchanged; write clear in it to delete the day's items, mood and notes.\n")
+
+	for _, date := range r.dates() {
+		content.WriteString("\n")
+		content.WriteString(formatSectionHeading(date) + "\n")
+
+		statusUpdate := bases[date]
+		if statusUpdate == nil {
+			continue
+		}
+		for _, item := range editedItems(statusUpdate) {
+			content.WriteString(formatItemLine(item, refs))
+		}
+		content.WriteString(formatFieldLines("mood", singleLine(statusUpdate.Mood)))
+		content.WriteString(formatFieldLines("notes", statusUpdate.Notes))
+	}
+
+	content.WriteString("\n")
+	writeEditHelp(&content)
+	content.WriteString("#\n")
+	content.WriteString("# Keep the ## headings, each starts the lines of its day\n")
+	content.WriteString("#   clear           = delete everything of the day\n")
+
+	return writeRangeEditDraft(r, content.String(), bases, refs)
+}
+
+// rangeSectionParser collects the lines of one day of a range edit
+type rangeSectionParser struct {
+	date      string
+	parser    *editLineParser
+	lines     int
+	clearLine int
+}
+
+// parseRangeEditedFile parses the draft of a range into the days that have
+// lines or were cleared. Invalid lines are reported together as *editProblems.
+func parseRangeEditedFile(filename string, bases map[string]*StatusUpdate, refs map[string]string) ([]rangeEditSection, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	dates := make([]string, 0, len(bases))
+	for date := range bases {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	headings := make(map[string]string, len(dates))
+	for _, date := range dates {
+		headings[formatSectionHeading(date)] = date
+	}
+
+	// References are unique in the whole buffer, so an item moved to another
+	// day is deleted from its day and added to the new one
+	usedIDs := make(map[string]bool)
+	headingLines := make(map[string]int)
+	var sections []*rangeSectionParser
+	var current *rangeSectionParser
+	var problems []editProblem
+	ignoring := false
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if matches := sectionHeadingRegex.FindStringSubmatch(line); matches != nil {
+			heading := "## " + strings.TrimSpace(matches[1])
+			date, ok := headings[heading]
+			switch {
+			case !ok:
+				problems = append(problems, editProblem{line: lineNumber, message: "unknown day, keep the ## headings as they were"})
+			case headingLines[date] > 0:
+				problems = append(problems, editProblem{line: lineNumber, message: "duplicate day, keep one section per day", seeLine: headingLines[date]})
+			default:
+				headingLines[date] = lineNumber
+				current = &rangeSectionParser{date: date, parser: newEditLineParser(refs, usedIDs)}
+				sections = append(sections, current)
+				ignoring = false
+				continue
+			}
+			// The lines under a heading that's already reported are skipped
+			current, ignoring = nil, true
+			continue
+		}
+
+		if line == "" || strings.HasPrefix(line, "#") || ignoring {
+			continue
+		}
+		if current == nil {
+			problems = append(problems, editProblem{line: lineNumber, message: "line outside a day, move it under a ## heading"})
+			continue
+		}
+		if line == clearSectionLine {
+			current.clearLine = lineNumber
+			continue
+		}
+		current.parser.parseLine(lineNumber, line)
+		current.lines++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading file: %v", err)
+	}
+
+	byDate := make(map[string]*rangeSectionParser, len(sections))
+	for _, section := range sections {
+		problems = append(problems, section.parser.problems...)
+		byDate[section.date] = section
+	}
+
+	var result []rangeEditSection
+	for _, date := range dates {
+		section := byDate[date]
+		switch {
+		case section != nil && section.clearLine > 0 && section.lines > 0:
+			problems = append(problems, editProblem{line: section.clearLine, message: "clear deletes everything of the day, remove it or the day's other lines"})
+		case section != nil && section.clearLine > 0:
+			result = append(result, rangeEditSection{date: date, parsed: &ParsedStatusUpdate{Items: []EditedItem{}}})
+		case section != nil && section.lines > 0:
+			result = append(result, rangeEditSection{date: date, parsed: section.parser.result})
+		default:
+			// A day left alone still loses the items moved to other days
+			if parsed := withoutMovedItems(bases[date], usedIDs); parsed != nil {
+				result = append(result, rangeEditSection{date: date, parsed: parsed})
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.SliceStable(problems, func(i, j int) bool { return problems[i].line < problems[j].line })
+		return nil, &editProblems{problems: problems}
+	}
+	return result, nil
+}
+
+// withoutMovedItems returns a day left alone in a range edit without the items
+// used in other days, or nil if none were
+func withoutMovedItems(statusUpdate *StatusUpdate, usedIDs map[string]bool) *ParsedStatusUpdate {
+	if statusUpdate == nil {
+		return nil
+	}
+	parsed := &ParsedStatusUpdate{Items: []EditedItem{}, Mood: statusUpdate.Mood, Notes: statusUpdate.Notes}
+	for _, item := range editedItems(statusUpdate) {
+		if !usedIDs[item.ID] {
+			parsed.Items = append(parsed.Items, item)
+		}
+	}
+	if len(parsed.Items) == len(statusUpdate.Items) {
+		return nil
+	}
+	return parsed
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// maxItemLength is the longest item the edit buffer accepts, in characters
+const maxItemLength = 500
+
+// editErrorPrefix starts the comments that point out problems in the buffer
+const editErrorPrefix = "# ERROR"
+
+// errEditAborted reports an edit the user abandoned by emptying the buffer
+var errEditAborted = errors.New("edit aborted")
+
+// editProblem is a line of the edit buffer that can't be saved, pointing to
+// a related line if seeLine is set
+type editProblem struct {
+	line    int
+	message string
+	seeLine int
+}
+
+// describe returns the message of a problem, with the related line numbered
+// through lineNumber
+func (p editProblem) describe(lineNumber func(int) int) string {
+	if p.seeLine == 0 {
+		return p.message
+	}
+	return fmt.Sprintf("%s (see line %d)", p.message, lineNumber(p.seeLine))
+}
+
+// editProblems is every problem found in an edit buffer
+type editProblems struct {
+	problems []editProblem
+}
+
+func (e *editProblems) Error() string {
+	messages := make([]string, 0, len(e.problems))
+	for _, problem := range e.problems {
+		messages = append(messages, fmt.Sprintf("line %d: %s", problem.line, problem.describe(func(line int) int { return line })))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// singleLine collapses a mood written over several lines, as the buffer
+// keeps the mood on one line
+func singleLine(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	line := strings.Join(strings.Fields(*value), " ")
+	return &line
+}
+
+// editUntilValid opens a draft in the editor until it parses, see
+// reopenUntilValid
+func editUntilValid(ctx context.Context, draftPath string, refs map[string]string) (*ParsedStatusUpdate, error) {
+	var parsed *ParsedStatusUpdate
+	err := reopenUntilValid(ctx, draftPath, func() (bool, error) {
+		var err error
+		parsed, err = parseEditedFile(draftPath, refs)
+		return err == nil && isEmptyEdit(parsed), err
+	})
+	return parsed, err
+}
+
+// reopenUntilValid opens a draft in the editor until parse accepts it.
+// Problems are written into the draft as comments next to the offending lines
+// and the editor reopens; emptying the draft then aborts with errEditAborted.
+// parse reports whether the draft is empty.
+func reopenUntilValid(ctx context.Context, draftPath string, parse func() (bool, error)) error {
+	annotated := false
+	for {
+		before, err := os.ReadFile(draftPath)
+		if err != nil {
+			return fmt.Errorf("failed to read draft: %v", err)
+		}
+		if err := openEditor(draftPath); err != nil {
+			return fmt.Errorf("failed to open editor: %v", err)
+		}
+		if ctx.Err() != nil {
+			return validationError("interrupted")
+		}
+
+		empty, err := parse()
+		var problems *editProblems
+		if errors.As(err, &problems) {
+			after, readErr := os.ReadFile(draftPath)
+			if readErr != nil {
+				return fmt.Errorf("failed to read draft: %v", readErr)
+			}
+			// An editor that returns right away would reopen forever
+			if annotated && bytes.Equal(before, after) {
+				return validationError("edit still has problems: %v", problems)
+			}
+			if err := annotateEditProblems(draftPath, after, problems.problems); err != nil {
+				return err
+			}
+			annotated = true
+			color.New(color.FgYellow).Printf("⧗ %d problem(s) in the edit, reopening the editor\n", len(problems.problems))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse edited file: %v", err)
+		}
+
+		if annotated && empty {
+			return errEditAborted
+		}
+		return nil
+	}
+}
+
+// isEmptyEdit reports whether an edit has no items, mood or notes
+func isEmptyEdit(parsed *ParsedStatusUpdate) bool {
+	return len(parsed.Items) == 0 && parsed.Mood == nil && parsed.Notes == nil
+}
+
+// annotateEditProblems rewrites the draft with a comment above each offending
+// line, replacing the comments of the previous attempt. The line numbers are
+// those of the rewritten draft, as the editor shows them.
+func annotateEditProblems(draftPath string, content []byte, problems []editProblem) error {
+	byLine := make(map[int][]editProblem)
+	for _, problem := range problems {
+		byLine[problem.line] = append(byLine[problem.line], problem)
+	}
+
+	// Number the lines as they'll be once annotated, dropping the comments of
+	// the previous attempt
+	oldLines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	newNumbers := make(map[int]int, len(oldLines))
+	next := 2 // after the heading
+	for i, line := range oldLines {
+		if strings.HasPrefix(strings.TrimSpace(line), editErrorPrefix) {
+			continue
+		}
+		next += len(byLine[i+1])
+		newNumbers[i+1] = next
+		next++
+	}
+	lineNumber := func(line int) int { return newNumbers[line] }
+
+	lines := []string{editErrorPrefix + ": fix the lines marked below and save, or delete everything to abort"}
+	for i, line := range oldLines {
+		if strings.HasPrefix(strings.TrimSpace(line), editErrorPrefix) {
+			continue
+		}
+		for _, problem := range byLine[i+1] {
+			lines = append(lines, fmt.Sprintf("%s line %d: %s", editErrorPrefix, lineNumber(i+1), problem.describe(lineNumber)))
+		}
+		lines = append(lines, line)
+	}
+
+	return writeFileAtomic(draftPath, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+}
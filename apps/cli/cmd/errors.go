@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"asyncstatus.com/cli/client"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes returned by every command. These are part of the CLI contract
+// so scripts and git hooks can react to the failure class.
+const (
+	exitOK         = 0
+	exitError      = 1 // unclassified failure
+	exitValidation = 2 // invalid input or arguments, or rejected by the server as invalid
+	exitAuth       = 3 // not logged in, expired or rejected credentials
+	exitNetwork    = 4 // the API could not be reached
+	exitServer     = 5 // the API failed to process the request
+)
+
+// errorClass identifies the kind of failure for exit codes, hints and JSON output
+type errorClass string
+
+const (
+	errorClassUnknown    errorClass = "error"
+	errorClassValidation errorClass = "validation_error"
+	errorClassAuth       errorClass = "auth_error"
+	errorClassNetwork    errorClass = "network_error"
+	errorClassServer     errorClass = "server_error"
+)
+
+// errorFormat selects how failures are reported (text or json)
+var errorFormat string
+
+// cliError is a classified failure reported by a command
+type cliError struct {
+	class   errorClass
+	message string
+	status  int
+	hint    string
+	err     error
+}
+
+// Error implements the error interface
+func (e *cliError) Error() string {
+	return e.message
+}
+
+// Unwrap returns the underlying error
+func (e *cliError) Unwrap() error {
+	return e.err
+}
+
+// exitCode returns the process exit code for the error class
+func (e *cliError) exitCode() int {
+	switch e.class {
+	case errorClassValidation:
+		return exitValidation
+	case errorClassAuth:
+		return exitAuth
+	case errorClassNetwork:
+		return exitNetwork
+	case errorClassServer:
+		return exitServer
+	}
+	return exitError
+}
+
+// validationError creates an error for invalid user input
+func validationError(format string, args ...any) error {
+	return &cliError{class: errorClassValidation, message: fmt.Sprintf(format, args...)}
+}
+
+// authError creates an error for missing or rejected credentials
+func authError(format string, args ...any) error {
+	return &cliError{
+		class:   errorClassAuth,
+		message: fmt.Sprintf(format, args...),
+		hint:    authHint(),
+	}
+}
+
+// authHint tells how to fix missing or rejected credentials
+func authHint() string {
+	if tokenFromEnv() != "" {
+		return "replace ASYNCSTATUS_TOKEN with a new token from: asyncstatus token create"
+	}
+	return "run: asyncstatus login"
+}
+
+// networkError wraps an error caused by the API being unreachable
+func networkError(err error) error {
+	return &cliError{
+		class:   errorClassNetwork,
+		message: err.Error(),
+		hint:    fmt.Sprintf("check your network connection and that %s is reachable", getAPIURL()),
+		err:     err,
+	}
+}
+
+// usageArgs wraps a cobra argument validator so its errors are reported as validation errors
+func usageArgs(validator cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := validator(cmd, args); err != nil {
+			return &cliError{
+				class:   errorClassValidation,
+				message: err.Error(),
+				hint:    fmt.Sprintf("run: %s --help", cmd.CommandPath()),
+				err:     err,
+			}
+		}
+		return nil
+	}
+}
+
+// classifyError converts any error into a cliError with a class and hint
+func classifyError(err error) *cliError {
+	var cliErr *cliError
+	if errors.As(err, &cliErr) {
+		return cliErr
+	}
+
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		// Keep any context added by callers but show the server message instead of the raw body
+		classified := &cliError{
+			message: strings.Replace(err.Error(), apiErr.Error(), apiErr.Message(), 1),
+			status:  apiErr.Status,
+			err:     err,
+		}
+		switch {
+		case apiErr.Status == http.StatusUnauthorized || apiErr.Status == http.StatusForbidden:
+			classified.class = errorClassAuth
+			classified.hint = authHint()
+		case apiErr.Status == http.StatusTooManyRequests || apiErr.Status >= 500:
+			classified.class = errorClassServer
+			classified.hint = "the AsyncStatus API could not process the request, try again shortly"
+		default:
+			classified.class = errorClassValidation
+			classified.hint = "the request was rejected, check the input and try again"
+		}
+		return classified
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return networkError(err).(*cliError)
+	}
+
+	return &cliError{class: errorClassUnknown, message: err.Error(), err: err}
+}
+
+// reportError prints the error in the selected format and returns the exit code
+func reportError(err error) int {
+	classified := classifyError(err)
+
+	if errorFormat == "json" {
+		payload := struct {
+			Code    errorClass `json:"code"`
+			Message string     `json:"message"`
+			Status  int        `json:"status"`
+			Hint    string     `json:"hint"`
+		}{
+			Code:    classified.class,
+			Message: classified.message,
+			Status:  classified.status,
+			Hint:    classified.hint,
+		}
+		jsonData, _ := json.Marshal(payload)
+		fmt.Fprintln(os.Stderr, string(jsonData))
+		return classified.exitCode()
+	}
+
+	color.New(color.FgRed).Fprintf(os.Stderr, "⧗ failed: %s\n", classified.message)
+	if classified.hint != "" {
+		color.New(color.FgHiBlack).Fprintf(os.Stderr, "  %s\n", classified.hint)
+	}
+	return classified.exitCode()
+}
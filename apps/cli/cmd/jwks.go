@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"asyncstatus.com/cli/client"
+	"github.com/MicahParks/keyfunc/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwksCacheTTL is how long a cached key set is used before it is fetched again
+const jwksCacheTTL = 24 * time.Hour
+
+// tokenSigningMethods are the algorithms accepted for token signatures.
+// Only asymmetric ones, so a key set can never be used as a shared secret.
+var tokenSigningMethods = []string{"EdDSA", "ES256", "ES384", "ES512", "RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}
+
+// cachedJWKS is the key set of an API stored on disk
+type cachedJWKS struct {
+	URL       string          `json:"url"`
+	FetchedAt time.Time       `json:"fetchedAt"`
+	JWKS      json.RawMessage `json:"jwks"`
+}
+
+// getJWKSCachePath returns the path of the cached key set of the active profile
+func getJWKSCachePath() string {
+	return filepath.Join(getActiveProfileCacheDir(), "jwks.json")
+}
+
+// readJWKSCache returns the cached key set if it belongs to apiURL
+func readJWKSCache(apiURL string) (*cachedJWKS, bool) {
+	content, err := os.ReadFile(getJWKSCachePath())
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cachedJWKS
+	if err := json.Unmarshal(content, &entry); err != nil || entry.URL != apiURL {
+		return nil, false
+	}
+
+	return &entry, true
+}
+
+// writeJWKSCache stores a key set. The cache is best effort, so failures are ignored.
+func writeJWKSCache(entry *cachedJWKS) {
+	if err := os.MkdirAll(filepath.Dir(getJWKSCachePath()), 0700); err != nil {
+		return
+	}
+
+	jsonData, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	_ = writeFileAtomic(getJWKSCachePath(), jsonData, 0600)
+}
+
+// loadJWKS returns the key set of an API, from the cache while it is recent
+// unless refresh is set. A stale cached copy is used if fetching fails.
+// It reports whether the key set came from the cache.
+func loadJWKS(ctx context.Context, apiURL string, refresh bool) (*keyfunc.JWKS, bool, error) {
+	cached, ok := readJWKSCache(apiURL)
+	if ok && !refresh && time.Since(cached.FetchedAt) < jwksCacheTTL {
+		if jwks, err := keyfunc.NewJSON(cached.JWKS); err == nil {
+			return jwks, true, nil
+		}
+	}
+
+	raw, err := client.New(apiURL, "", client.WithUserAgent("AsyncStatus-CLI/"+Version)).JWKS(ctx)
+	if err != nil {
+		if ok && !refresh {
+			if jwks, jsonErr := keyfunc.NewJSON(cached.JWKS); jsonErr == nil {
+				return jwks, true, nil
+			}
+		}
+		return nil, false, fmt.Errorf("failed to fetch signing keys from %s: %w", apiURL, err)
+	}
+
+	jwks, err := keyfunc.NewJSON(raw)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid signing keys from %s: %v", apiURL, err)
+	}
+	writeJWKSCache(&cachedJWKS{URL: apiURL, FetchedAt: time.Now(), JWKS: raw})
+
+	return jwks, false, nil
+}
+
+// verifyToken checks a token's signature against the key set of the API and
+// its issuer, audience and expiry, and returns its claims. A key ID missing
+// from the cached key set makes it fetch the key set again, so rotated keys
+// are picked up.
+func verifyToken(ctx context.Context, apiURL, token string) (*tokenClaims, error) {
+	apiURL = strings.TrimRight(apiURL, "/")
+
+	jwks, fromCache, err := loadJWKS(ctx, apiURL, false)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := parseVerifiedToken(token, jwks, apiURL)
+	if err != nil && fromCache && errors.Is(err, keyfunc.ErrKIDNotFound) {
+		jwks, _, err = loadJWKS(ctx, apiURL, true)
+		if err != nil {
+			return nil, err
+		}
+		claims, err = parseVerifiedToken(token, jwks, apiURL)
+	}
+	if err != nil {
+		return nil, authError("token verification failed: %v", err)
+	}
+
+	return claims, nil
+}
+
+// parseVerifiedToken parses a token, checking it was issued by and for apiURL
+func parseVerifiedToken(token string, jwks *keyfunc.JWKS, apiURL string) (*tokenClaims, error) {
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(token, &claims, jwks.Keyfunc,
+		jwt.WithValidMethods(tokenSigningMethods),
+		jwt.WithIssuer(apiURL),
+		jwt.WithAudience(apiURL),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &claims, nil
+}
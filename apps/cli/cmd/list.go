@@ -1,120 +1,147 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
 	"strconv"
 	"strings"
+	"time"
 
+	"asyncstatus.com/cli/client"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
 // listCmd represents the list command
 var listCmd = &cobra.Command{
-	Use:   "list [days]",
+	Use:   "list [days|range]",
 	Short: "List recent status updates",
-	Long: `List recent status updates for the specified number of days (default: 1).
-Shows your status updates from the past few days in reverse chronological order,
-including links to view each one online.
+	Long: `List recent status updates for the specified number of days before today
+(default: 1), for a date or range of days, or for any range with --since and --until.
+Shows your status updates in reverse chronological order, including links
+to view each one online. Long ranges are fetched in parallel and shown as
+they arrive.
+
+Filters narrow what is shown: --type and --grep keep only matching items,
+--team, --has-notes and --mood keep only matching updates.
 
 Examples:
-  asyncstatus list         # List status updates from today only
-  asyncstatus list 3       # List status updates from the past 3 days
-  asyncstatus list 7       # List status updates from the past 7 days`,
-	Args: cobra.MaximumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		days := 1 // default
-		if len(args) == 1 {
-			if d, err := strconv.Atoi(args[0]); err != nil {
-				fmt.Printf("❌ Invalid number of days: %s\n", args[0])
-				return
-			} else {
-				days = d
+  asyncstatus list                   # List status updates from yesterday and today
+  asyncstatus list 3                 # List status updates from the past 3 days and today
+  asyncstatus list 7                 # List status updates from the past 7 days and today
+  asyncstatus list "last week"       # List status updates from last week
+  asyncstatus list monday..today     # List status updates since Monday
+  asyncstatus list --since 2024-01-01 --until 2024-03-31
+  asyncstatus list --since -90d --type blocker
+  asyncstatus list --since "last month" --grep "(?i)deploy" --limit 5
+  asyncstatus list 7 -o csv          # One row per item for spreadsheets
+  asyncstatus list "last week" --template oneline
+  asyncstatus list 7 --format '{{date .}} {{join ", " (contents .Items)}}'`,
+	Args: usageArgs(cobra.MaximumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 && listSinceFlag == "" && listUntilFlag == "" {
+			if days, err := strconv.Atoi(args[0]); err == nil {
+				if days < 1 {
+					return validationError("days must be at least 1")
+				}
+				now := nowInTimezone()
+				today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+				heading := fmt.Sprintf("past %d days and today", days)
+				if days == 1 {
+					heading = "yesterday and today"
+				}
+				return handleListStatus(cmd.Context(), dateRange{start: today.AddDate(0, 0, -days), end: today}, heading)
 			}
 		}
 
-		if err := handleListStatus(days); err != nil {
-			fmt.Printf("❌ Failed to retrieve status updates: %v\n", err)
-			fmt.Println("   Make sure you're logged in: asyncstatus login")
+		dateRange, err := getListRange(args)
+		if err != nil {
+			return err
+		}
+		heading := describeDateRange(dateRange)
+		if len(args) == 0 && listSinceFlag == "" {
+			heading = "yesterday and today"
 		}
+		return handleListStatus(cmd.Context(), dateRange, heading)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(listCmd)
+	addCacheFlags(listCmd)
+	addListFlags(listCmd)
+	addTemplateFlags(listCmd)
 }
 
 // ListStatusUpdatesResponse represents the API response for listing status updates
-type ListStatusUpdatesResponse struct {
-	StatusUpdates []StatusUpdate `json:"statusUpdates"`
-	Message       string         `json:"message"`
-}
+type ListStatusUpdatesResponse = client.ListStatusUpdatesResponse
 
-// handleListStatus processes retrieving recent status updates
-func handleListStatus(days int) error {
-	if days < 1 || days > 30 {
-		return fmt.Errorf("days must be between 1 and 30")
-	}
-
-	endpoint := fmt.Sprintf("/cli/status-updates/recent?days=%d", days)
-	client, req, err := makeAuthenticatedRequest("GET", endpoint)
+// handleListStatus processes retrieving the status updates of a range of days,
+// printing each as soon as it arrives
+func handleListStatus(ctx context.Context, dateRange dateRange, heading string) error {
+	policy, err := getCachePolicy()
 	if err != nil {
 		return err
 	}
-
-	// Send request
-	resp, err := client.Do(req)
+	filter, err := newStatusUpdateFilter()
 	if err != nil {
-		return fmt.Errorf("failed to send request: %v", err)
+		return err
 	}
-	defer resp.Body.Close()
-
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	tmpl, err := getStatusUpdateTemplate()
 	if err != nil {
-		return fmt.Errorf("failed to read response: %v", err)
+		return err
 	}
-
-	// Check response status
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("server error (status %d): %s", resp.StatusCode, string(body))
+	if tmpl != nil {
+		return handleListTemplate(ctx, dateRange, policy, filter, tmpl)
+	}
+	if machineOutput() {
+		return handleListOutput(ctx, dateRange, policy, filter)
 	}
 
-	// Parse response
-	var response ListStatusUpdatesResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return fmt.Errorf("failed to parse response: %v", err)
+	headerColor := color.New(color.FgWhite, color.Bold)
+	count := 0
+	err = streamStatusUpdates(ctx, dateRange, policy, func(statusUpdate StatusUpdate) bool {
+		statusUpdate, ok := filter.apply(statusUpdate)
+		if !ok {
+			return true
+		}
+		
+		if count == 0 {
+			headerColor.Print("⧗ ")
+			headerColor.Println(heading)
+			fmt.Println()
+		} else {
+			color.New(color.FgHiBlack).Println("  ────────────────────────────────────")
+		}
+		count++
+		displayStatusUpdateSummary(&statusUpdate, count)
+		
+		return listLimitFlag == 0 || count < listLimitFlag
+	})
+	if err != nil {
+		return err
 	}
 
-	// Display the status updates
-	if len(response.StatusUpdates) == 0 {
-		if days == 1 {
+	// Nothing matched in the whole range
+	if count == 0 {
+		if filter.active() {
+			color.New(color.FgHiBlack).Printf("⧗ no updates matching the filters for %s\n", describeDateRange(dateRange))
+			return nil
+		}
+		if heading == "today's updates" {
 			color.New(color.FgHiBlack).Println("⧗ no updates found for today")
 		} else {
-			color.New(color.FgHiBlack).Printf("⧗ no updates found for the past %d days\n", days)
+			color.New(color.FgHiBlack).Printf("⧗ no updates found for %s\n", describeDateRange(dateRange))
 		}
 		color.New(color.FgHiBlack).Println("  run:", color.New(color.FgWhite).Sprint("asyncstatus done \"your task\""), "to create one")
 		return nil
 	}
 
-	headerColor := color.New(color.FgWhite, color.Bold)
 	countColor := color.New(color.FgCyan)
-	
-	headerColor.Print("⧗ ")
-	if days == 1 {
-		headerColor.Println("today's updates")
+	if listLimitFlag > 0 && count == listLimitFlag {
+		countColor.Printf("  %d update(s), stopped at --limit\n", count)
 	} else {
-		headerColor.Printf("past %d days\n", days)
-	}
-	countColor.Printf("  %d update(s)\n\n", len(response.StatusUpdates))
-
-	for i, statusUpdate := range response.StatusUpdates {
-		displayStatusUpdateSummary(&statusUpdate, i+1)
-		if i < len(response.StatusUpdates)-1 {
-			color.New(color.FgHiBlack).Println("  ────────────────────────────────────")
-		}
+		countColor.Printf("  %d update(s)\n", count)
 	}
 
 	return nil
@@ -129,7 +156,7 @@ func displayStatusUpdateSummary(statusUpdate *StatusUpdate, index int) {
 	teamColor := color.New(color.FgMagenta)
 	
 	indexColor.Printf("  %d. ", index)
-	dateColor.Println(statusUpdate.EffectiveFrom.Format("Monday, January 2"))
+	dateColor.Println(formatDate(statusUpdateDay(statusUpdate)))
 	
 	userColor.Print("     ")
 	userColor.Print(statusUpdate.Member.User.Name)
@@ -207,7 +234,7 @@ func displayStatusUpdateSummary(statusUpdate *StatusUpdate, index int) {
 	}
 
 	timeColor := color.New(color.FgHiBlack)
-	timeColor.Printf("     %s", statusUpdate.UpdatedAt.Format("15:04"))
+	timeColor.Printf("     %s", formatTime(statusUpdate.UpdatedAt))
 	
 	// TODO: Add web URL link when organization slug is available in response
 	fmt.Println()
@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"asyncstatus.com/cli/client"
+	"github.com/spf13/cobra"
+)
+
+// listRangeConcurrency bounds the range requests list has in flight
+const listRangeConcurrency = 4
+
+var (
+	listSinceFlag    string
+	listUntilFlag    string
+	listTypeFlag     string
+	listTeamFlag     string
+	listGrepFlag     string
+	listHasNotesFlag bool
+	listMoodFlag     string
+	listLimitFlag    int
+)
+
+// addListFlags registers the range and filter flags of the list command
+func addListFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&listSinceFlag, "since", "", "First day to list, e.g. 2024-01-01, \"last month\" or -90d")
+	cmd.Flags().StringVar(&listUntilFlag, "until", "", "Last day to list (default today)")
+	cmd.Flags().StringVar(&listTypeFlag, "type", "", "Only items of this type: done, progress or blocker")
+	cmd.Flags().StringVar(&listTeamFlag, "team", "", "Only updates posted to this team, by name or ID")
+	cmd.Flags().StringVar(&listGrepFlag, "grep", "", "Only items, moods or notes matching this regular expression")
+	cmd.Flags().BoolVar(&listHasNotesFlag, "has-notes", false, "Only updates with notes")
+	cmd.Flags().StringVar(&listMoodFlag, "mood", "", "Only updates whose mood contains this text")
+	cmd.Flags().IntVar(&listLimitFlag, "limit", 0, "Stop after this many updates")
+}
+
+// getListRange returns the days selected by the --since and --until flags,
+// or by the positional argument, which is either a number of past days or
+// a date expression
+func getListRange(args []string) (dateRange, error) {
+	now := nowInTimezone()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if listSinceFlag != "" || listUntilFlag != "" {
+		if len(args) > 0 {
+			return dateRange{}, validationError("pass either days or a range, or --since and --until, not both")
+		}
+		if listSinceFlag == "" {
+			return dateRange{}, validationError("--until needs --since, e.g. --since 2024-01-01 --until 2024-03-31")
+		}
+		since, err := parseDateExpr(listSinceFlag, now)
+		if err != nil {
+			return dateRange{}, err
+		}
+		until := dateRange{start: today, end: today}
+		if listUntilFlag != "" {
+			if until, err = parseDateExpr(listUntilFlag, now); err != nil {
+				return dateRange{}, err
+			}
+		}
+		if until.end.After(today) {
+			until.end = today
+		}
+		if since.start.After(until.end) {
+			return dateRange{}, validationError("--since %s is after --until %s", since.start.Format("2006-01-02"), until.end.Format("2006-01-02"))
+		}
+		return dateRange{start: since.start, end: until.end}, nil
+	}
+
+	if len(args) == 0 {
+		return dateRange{start: today.AddDate(0, 0, -1), end: today}, nil
+	}
+	return parseDateRange(args[0], 0)
+}
+
+// statusUpdateFilter selects the status updates and items list shows
+type statusUpdateFilter struct {
+	itemType client.ItemType
+	team     string
+	grep     *regexp.Regexp
+	hasNotes bool
+	mood     string
+}
+
+// newStatusUpdateFilter builds the filter from the list flags
+func newStatusUpdateFilter() (*statusUpdateFilter, error) {
+	filter := &statusUpdateFilter{
+		team:     strings.ToLower(listTeamFlag),
+		hasNotes: listHasNotesFlag,
+		mood:     strings.ToLower(listMoodFlag),
+	}
+
+	switch listTypeFlag {
+	case "":
+	case "done", "progress", "blocker":
+		filter.itemType = client.ItemType(listTypeFlag)
+	default:
+		return nil, validationError("invalid --type %q, expected done, progress or blocker", listTypeFlag)
+	}
+
+	if listGrepFlag != "" {
+		grep, err := regexp.Compile(listGrepFlag)
+		if err != nil {
+			return nil, validationError("invalid --grep pattern: %v", err)
+		}
+		filter.grep = grep
+	}
+
+	if listLimitFlag < 0 {
+		return nil, validationError("--limit must be positive")
+	}
+
+	return filter, nil
+}
+
+// active reports whether the filter excludes anything
+func (f *statusUpdateFilter) active() bool {
+	return f.itemType != "" || f.team != "" || f.grep != nil || f.hasNotes || f.mood != ""
+}
+
+// apply returns the status update narrowed to the matching items, and whether
+// it matches at all. --type and --grep narrow the items; an update is kept when
+// items remain or --grep matches its mood or notes.
+func (f *statusUpdateFilter) apply(statusUpdate StatusUpdate) (StatusUpdate, bool) {
+	if f.team != "" && (statusUpdate.Team == nil || (strings.ToLower(statusUpdate.Team.Name) != f.team && strings.ToLower(statusUpdate.Team.ID) != f.team)) {
+		return statusUpdate, false
+	}
+	if f.hasNotes && (statusUpdate.Notes == nil || strings.TrimSpace(*statusUpdate.Notes) == "") {
+		return statusUpdate, false
+	}
+	if f.mood != "" && (statusUpdate.Mood == nil || !strings.Contains(strings.ToLower(*statusUpdate.Mood), f.mood)) {
+		return statusUpdate, false
+	}
+	if f.itemType == "" && f.grep == nil {
+		return statusUpdate, true
+	}
+
+	var items []StatusUpdateItem
+	for _, item := range statusUpdate.Items {
+		if f.itemType != "" && item.Type() != f.itemType {
+			continue
+		}
+		if f.grep != nil && !f.grep.MatchString(item.Content) {
+			continue
+		}
+		items = append(items, item)
+	}
+
+	matchesText := false
+	if f.grep != nil {
+		matchesText = (statusUpdate.Mood != nil && f.grep.MatchString(*statusUpdate.Mood)) ||
+			(statusUpdate.Notes != nil && f.grep.MatchString(*statusUpdate.Notes))
+	}
+	if len(items) == 0 && !matchesText {
+		return statusUpdate, false
+	}
+	if len(items) > 0 {
+		statusUpdate.Items = items
+	}
+	return statusUpdate, true
+}
+
+// handleListOutput prints the status updates of a range in a machine-readable
+// format, streaming the formats that allow it
+func handleListOutput(ctx context.Context, r dateRange, policy cachePolicy, filter *statusUpdateFilter) error {
+	writer := newStatusUpdateWriter(getOutputFormat(), r.start.Format("2006-01-02"), r.end.Format("2006-01-02"))
+
+	count := 0
+	var writeErr error
+	err := streamStatusUpdates(ctx, r, policy, func(statusUpdate StatusUpdate) bool {
+		statusUpdate, ok := filter.apply(statusUpdate)
+		if !ok {
+			return true
+		}
+		if writeErr = writer.write(&statusUpdate); writeErr != nil {
+			return false
+		}
+		count++
+		return listLimitFlag == 0 || count < listLimitFlag
+	})
+	if err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+
+	return writer.flush()
+}
+
+// handleListTemplate prints the status updates of a range with a template
+func handleListTemplate(ctx context.Context, r dateRange, policy cachePolicy, filter *statusUpdateFilter, tmpl *template.Template) error {
+	count := 0
+	var templateErr error
+	err := streamStatusUpdates(ctx, r, policy, func(statusUpdate StatusUpdate) bool {
+		statusUpdate, ok := filter.apply(statusUpdate)
+		if !ok {
+			return true
+		}
+		if templateErr = executeStatusUpdateTemplate(tmpl, &statusUpdate); templateErr != nil {
+			return false
+		}
+		count++
+		return listLimitFlag == 0 || count < listLimitFlag
+	})
+	if err != nil {
+		return err
+	}
+	return templateErr
+}
+
+// splitDateRange splits a range into chunks of at most size days, newest first
+func splitDateRange(r dateRange, size int) []dateRange {
+	var chunks []dateRange
+	for end := r.end; !end.Before(r.start); end = end.AddDate(0, 0, -size) {
+		start := end.AddDate(0, 0, 1-size)
+		if start.Before(r.start) {
+			start = r.start
+		}
+		chunks = append(chunks, dateRange{start: start, end: end})
+	}
+	return chunks
+}
+
+// rangeChunkResult is the outcome of fetching one chunk of a range
+type rangeChunkResult struct {
+	statusUpdates []StatusUpdate
+	err           error
+}
+
+// streamStatusUpdates fetches the status updates of a range newest first and
+// passes each to emit as soon as it and everything newer has arrived. The range
+// is fetched in chunks the API accepts, several at a time; emit returning false
+// stops the remaining requests.
+func streamStatusUpdates(ctx context.Context, r dateRange, policy cachePolicy, emit func(StatusUpdate) bool) error {
+	apiClient, err := newAPIClient(ctx)
+	if err != nil {
+		return err
+	}
+	// The workers share the cache resolved here, so they never load the config
+	cache := openStatusUpdateCache()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	chunks := splitDateRange(r, client.MaxRangeDays)
+	results := make([]chan rangeChunkResult, len(chunks))
+	for i := range results {
+		results[i] = make(chan rangeChunkResult, 1)
+	}
+
+	go func() {
+		slots := make(chan struct{}, listRangeConcurrency)
+		for i, chunk := range chunks {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				results[i] <- rangeChunkResult{err: ctx.Err()}
+				continue
+			}
+			go func(i int, chunk dateRange) {
+				defer func() { <-slots }()
+				statusUpdates, err := fetchStatusUpdateChunk(ctx, apiClient, cache, chunk, policy)
+				results[i] <- rangeChunkResult{statusUpdates: statusUpdates, err: err}
+			}(i, chunk)
+		}
+	}()
+
+	for i := range chunks {
+		result := <-results[i]
+		if result.err != nil {
+			return result.err
+		}
+		for _, statusUpdate := range result.statusUpdates {
+			if !emit(statusUpdate) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// fetchStatusUpdateChunk returns the status updates of a chunk of days, newest
+// first. Chunks fully in the cache are served from it according to the cache
+// policy; fetched chunks are written back to the cache per day.
+func fetchStatusUpdateChunk(ctx context.Context, apiClient *client.Client, cache statusUpdateCache, chunk dateRange, policy cachePolicy) ([]StatusUpdate, error) {
+	dates := chunk.dates()
+
+	if policy != cachePolicyFresh {
+		entries := make(map[string]*cachedStatusUpdate, len(dates))
+		for _, date := range dates {
+			entry, ok := cache.read(date)
+			if !ok || (policy == cachePolicyDefault && !entry.isComplete()) {
+				entries = nil
+				break
+			}
+			entries[date] = entry
+		}
+		if entries != nil {
+			var statusUpdates []StatusUpdate
+			for i := len(dates) - 1; i >= 0; i-- {
+				if statusUpdate := entries[dates[i]].StatusUpdate; statusUpdate != nil {
+					statusUpdates = append(statusUpdates, *statusUpdate)
+				}
+			}
+			return statusUpdates, nil
+		}
+	}
+
+	response, err := apiClient.ListStatusUpdatesInRange(ctx, dates[0], dates[len(dates)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[string]*StatusUpdate, len(response.StatusUpdates))
+	for i := range response.StatusUpdates {
+		statusUpdate := &response.StatusUpdates[i]
+		byDate[statusUpdateCacheKey(statusUpdate)] = statusUpdate
+	}
+	for _, date := range dates {
+		cache.write(date, byDate[date])
+	}
+
+	return response.StatusUpdates, nil
+}
@@ -2,24 +2,30 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/cookiejar"
-	"os"
+	"net/url"
 	"strings"
 	"syscall"
-	"time"
 
+	apiclient "asyncstatus.com/cli/client"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
 
 var (
-	loginEmail  string
-	authBaseURL string
+	loginEmail         string
+	authBaseURL        string
+	loginWeb           bool
+	loginDevice        bool
+	loginPasswordStdin bool
+	loginTokenStdin    bool
+	loginOTP           string
 )
 
 // loginCmd represents the login command
@@ -28,39 +34,74 @@ var loginCmd = &cobra.Command{
 	Short: "Login to AsyncStatus",
 	Long: `Login to your AsyncStatus account using your email and password.
 
+Accounts that sign in with GitHub, Slack, GitLab or Discord can log in through
+the browser with --web. On machines without a browser, --device prints a URL
+and a code to enter on another device.
+
+For scripts, --password-stdin reads the password from stdin, and --token-stdin
+stores a token (for example from "asyncstatus token create") without a
+password. Without a terminal the CLI never prompts and fails instead.
+
+Accounts with two-factor authentication are asked for a code from their
+authenticator app, or a backup code. Pass it with --otp in scripts.
+
+Credentials and the API URL are stored in the active profile, selected with
+--profile, ASYNCSTATUS_PROFILE or "asyncstatus context use".
+
 Examples:
   asyncstatus login
   asyncstatus login --email user@example.com
-  asyncstatus login --api-url https://dev.api.asyncstatus.com`,
-	Args: cobra.NoArgs,
-	Run: func(cmd *cobra.Command, args []string) {
-		handleLogin()
+  asyncstatus login --web
+  asyncstatus login --device
+  echo "$PASSWORD" | asyncstatus login --email user@example.com --password-stdin
+  echo "$TOKEN" | asyncstatus login --token-stdin
+  asyncstatus login --email user@example.com --otp 123456
+  asyncstatus login --api-url https://dev.api.asyncstatus.com
+  asyncstatus login --profile staging --api-url https://staging.api.asyncstatus.com`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleLogin(cmd.Context())
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(loginCmd)
 	loginCmd.Flags().StringVarP(&loginEmail, "email", "e", "", "Email address")
-	loginCmd.Flags().StringVar(&authBaseURL, "api-url", getDefaultAPIURL(), "API base URL")
+	loginCmd.Flags().BoolVar(&loginWeb, "web", false, "Log in through the browser")
+	loginCmd.Flags().BoolVar(&loginDevice, "device", false, "Log in with a code entered in a browser on any device")
+	loginCmd.Flags().BoolVar(&loginPasswordStdin, "password-stdin", false, "Read the password from stdin")
+	loginCmd.Flags().BoolVar(&loginTokenStdin, "token-stdin", false, "Read a token from stdin instead of logging in with a password")
+	loginCmd.Flags().StringVar(&loginOTP, "otp", "", "Two-factor authentication or backup code")
+	loginCmd.Flags().StringVar(&authBaseURL, "api-url", "", "API base URL (default: the profile's URL, ASYNCSTATUS_API_URL or production)")
 }
 
-// getDefaultAPIURL returns the default API URL based on environment
-func getDefaultAPIURL() string {
-	// Check if custom URL is set via environment variable
-	if url := os.Getenv("ASYNCSTATUS_API_URL"); url != "" {
-		return url
+// handleLogin processes the login flow
+func handleLogin(ctx context.Context) error {
+	if loginWeb || loginDevice {
+		if loginEmail != "" || loginPasswordStdin || loginTokenStdin {
+			return validationError("--web and --device can't be combined with other login methods")
+		}
+		return handleWebLogin(ctx, loginDevice)
+	}
+	
+	if loginTokenStdin {
+		if loginEmail != "" || loginPasswordStdin {
+			return validationError("--token-stdin can't be combined with --email or --password-stdin")
+		}
+		return handleTokenLogin(ctx)
+	}
+	
+	if authBaseURL == "" {
+		authBaseURL = getAPIURL()
 	}
 	
-	// Default to production
-	return "https://api.asyncstatus.com"
-}
-
-// handleLogin processes the login flow
-func handleLogin() {
 	email := loginEmail
 	
 	// Prompt for email if not provided
 	if email == "" {
+		if loginPasswordStdin || !stdinIsTerminal() {
+			return validationError("--email is required when stdin is not a terminal")
+		}
 		fmt.Print("email: ")
 		fmt.Scanln(&email)
 	}
@@ -68,38 +109,49 @@ func handleLogin() {
 	// Validate email
 	email = strings.TrimSpace(email)
 	if email == "" {
-		color.New(color.FgRed).Println("⧗ email is required")
-		os.Exit(1)
+		return validationError("email is required")
 	}
 	
 	if !isValidEmail(email) {
-		color.New(color.FgRed).Println("⧗ please enter a valid email address")
-		os.Exit(1)
+		return validationError("please enter a valid email address")
 	}
 	
-	fmt.Print("password: ")
-	passwordBytes, err := term.ReadPassword(int(syscall.Stdin))
-	fmt.Println()
-	
+	password, err := readLoginPassword()
 	if err != nil {
-		color.New(color.FgRed).Printf("⧗ error reading password: %v\n", err)
-		os.Exit(1)
+		return err
 	}
-	
-	password := string(passwordBytes)
 	if strings.TrimSpace(password) == "" {
-		color.New(color.FgRed).Println("⧗ password is required")
-		os.Exit(1)
+		return validationError("password is required")
 	}
 	
 	// Perform login
-	if err := performLogin(email, password); err != nil {
-		color.New(color.FgRed).Printf("⧗ login failed: %v\n", err)
-		os.Exit(1)
+	if err := performLogin(ctx, email, password); err != nil {
+		return fmt.Errorf("login failed: %w", err)
 	}
 	
 	color.New(color.FgGreen).Print("⧗ logged in as ")
 	color.New(color.FgCyan).Println(email)
+	return nil
+}
+
+// readLoginPassword reads the password from stdin with --password-stdin,
+// otherwise prompts for it without echo
+func readLoginPassword() (string, error) {
+	if loginPasswordStdin {
+		return readStdinSecret("password")
+	}
+	if !stdinIsTerminal() {
+		return "", validationError("stdin is not a terminal, use --password-stdin, --token-stdin, --device or ASYNCSTATUS_TOKEN")
+	}
+	
+	fmt.Print("password: ")
+	passwordBytes, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Println()
+	
+	if err != nil {
+		return "", fmt.Errorf("error reading password: %w", err)
+	}
+	return string(passwordBytes), nil
 }
 
 // isValidEmail performs basic email validation
@@ -122,7 +174,10 @@ type LoginRequest struct {
 
 // LoginResponse represents the login response
 type LoginResponse struct {
-	User struct {
+	// TwoFactorRedirect is set instead of the session when the account
+	// requires a second factor
+	TwoFactorRedirect bool `json:"twoFactorRedirect"`
+	User              struct {
 		ID    string `json:"id"`
 		Email string `json:"email"`
 		Name  string `json:"name"`
@@ -134,7 +189,7 @@ type LoginResponse struct {
 }
 
 // performLogin handles the actual authentication
-func performLogin(email, password string) error {
+func performLogin(ctx context.Context, email, password string) error {
 	color.New(color.FgHiBlack).Print("⧗ authenticating ")
 	color.New(color.FgCyan).Print(email)
 	color.New(color.FgHiBlack).Println("...")
@@ -157,7 +212,7 @@ func performLogin(email, password string) error {
 	}
 	
 	client := &http.Client{
-		Timeout: 30 * time.Second,
+		Timeout: getHTTPTimeout(),
 		Jar:     jar, // This will store session cookies from Better Auth
 	}
 	
@@ -173,16 +228,16 @@ func performLogin(email, password string) error {
 	
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to connect to AsyncStatus: %v", err)
+		return networkError(fmt.Errorf("failed to connect to AsyncStatus: %w", err))
 	}
 	defer resp.Body.Close()
 	
 	// Check for authentication errors
 	if resp.StatusCode == 401 {
-		return fmt.Errorf("invalid email or password")
+		return authError("invalid email or password")
 	} else if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("authentication failed (status %d): %s", resp.StatusCode, string(body))
+		return fmt.Errorf("authentication failed: %w", &apiclient.APIError{Status: resp.StatusCode, Body: string(body)})
 	}
 	
 	// Parse login response
@@ -191,6 +246,15 @@ func performLogin(email, password string) error {
 		return fmt.Errorf("failed to parse login response: %v", err)
 	}
 	
+	// The session is only set once the challenge is answered on the same client
+	if loginResp.TwoFactorRedirect {
+		twoFactorResp, err := completeTwoFactor(client)
+		if err != nil {
+			return err
+		}
+		loginResp = *twoFactorResp
+	}
+	
 	color.New(color.FgGreen).Print("  ✓ authenticated ")
 	color.New(color.FgCyan).Println(loginResp.User.Email)
 	color.New(color.FgHiBlack).Println("  retrieving token...")
@@ -198,11 +262,20 @@ func performLogin(email, password string) error {
 	// Now get the JWT token from the session (uses the same client with cookies)
 	jwtToken, err := getJWTToken(client)
 	if err != nil {
-		return fmt.Errorf("login successful but failed to get JWT token: %v", err)
+		return fmt.Errorf("login successful but failed to get JWT token: %w", err)
 	}
 	
+	// Only store a token that the API signed for itself
+	if _, err := verifyToken(ctx, authBaseURL, jwtToken); err != nil {
+		return fmt.Errorf("refusing to store token: %w", err)
+	}
+	color.New(color.FgHiBlack).Println("  ✓ token signature verified")
+	
+	// Keep the session cookie for the endpoints that don't accept JWT tokens
+	sessionCookie := sessionCookieHeader(jar)
+	
 	// Store credentials with JWT token
-	return storeCredentials(email, jwtToken)
+	return storeCredentials(email, jwtToken, sessionCookie)
 }
 
 // getJWTToken retrieves the JWT token from the /auth/token endpoint
@@ -217,13 +290,13 @@ func getJWTToken(client *http.Client) (string, error) {
 	
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to get JWT token: %v", err)
+		return "", networkError(fmt.Errorf("failed to get JWT token: %w", err))
 	}
 	defer resp.Body.Close()
 	
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("failed to get JWT token (status %d): %s", resp.StatusCode, string(body))
+		return "", &apiclient.APIError{Status: resp.StatusCode, Body: string(body)}
 	}
 	
 	// Check for JWT token in set-auth-jwt header (as per Better Auth docs)
@@ -271,16 +344,49 @@ func getJWTToken(client *http.Client) (string, error) {
 	return "", fmt.Errorf("no JWT token received from server")
 }
 
-// storeCredentials stores authentication credentials
-func storeCredentials(email, token string) error {
+// sessionCookieHeader returns the Better Auth cookies in the jar as a Cookie header value
+func sessionCookieHeader(jar http.CookieJar) string {
+	authURL, err := url.Parse(authBaseURL)
+	if err != nil {
+		return ""
+	}
+	
+	var cookies []string
+	for _, cookie := range jar.Cookies(authURL) {
+		cookies = append(cookies, cookie.Name+"="+cookie.Value)
+	}
+	return strings.Join(cookies, "; ")
+}
+
+// storeCredentials stores authentication credentials in the active profile,
+// creating it if needed
+func storeCredentials(email, token, sessionCookie string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	
+	name := config.activeProfileName()
+	if !profileNameRegex.MatchString(name) {
+		return validationError("invalid profile name %q, use letters, digits, '.', '_' and '-'", name)
+	}
+	
 	color.New(color.FgHiBlack).Print("  storing credentials for ")
 	color.New(color.FgCyan).Print(email)
-	color.New(color.FgHiBlack).Println("...")
+	color.New(color.FgHiBlack).Printf(" in profile %s...\n", name)
+	
+	profile := config.Profiles[name]
+	if profile == nil {
+		profile = &Profile{}
+		config.Profiles[name] = profile
+	}
+	profile.APIURL = authBaseURL
+	profile.Email = email
 	
-	config := &Config{
-		Email: email,
-		Token: token,
+	// The first profile logged in becomes the current one
+	if config.CurrentProfile == "" {
+		config.CurrentProfile = name
 	}
 	
-	return saveConfig(config)
+	return saveCredentials(config, name, &storedCredentials{Token: token, SessionCookie: sessionCookie})
 }
\ No newline at end of file
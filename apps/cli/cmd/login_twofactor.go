@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	apiclient "asyncstatus.com/cli/client"
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+// twoFactorAttempts is how many codes are asked for before giving up
+const twoFactorAttempts = 3
+
+// totpCodeRegex matches the codes of authenticator apps; anything else is
+// tried as a backup code
+var totpCodeRegex = regexp.MustCompile(`^\d{6}$`)
+
+// errInvalidTwoFactorCode is returned when the server rejects a code
+var errInvalidTwoFactorCode = errors.New("invalid two-factor code")
+
+// invalidTwoFactorCodeError reports a rejected code once no attempts are left
+func invalidTwoFactorCodeError() error {
+	return &cliError{
+		class:   errorClassAuth,
+		message: errInvalidTwoFactorCode.Error(),
+		hint:    "check the code in your authenticator app, or use one of your backup codes",
+		err:     errInvalidTwoFactorCode,
+	}
+}
+
+// completeTwoFactor answers the two-factor challenge of a sign in with a TOTP
+// or backup code. The client must be the one that signed in, since the
+// challenge is tied to a cookie in its jar. The code comes from --otp or is
+// prompted for, up to twoFactorAttempts times.
+func completeTwoFactor(client *http.Client) (*LoginResponse, error) {
+	color.New(color.FgHiBlack).Println("  two-factor authentication required")
+
+	if loginOTP != "" {
+		loginResp, err := verifyTwoFactorCode(client, loginOTP)
+		if errors.Is(err, errInvalidTwoFactorCode) {
+			return nil, invalidTwoFactorCodeError()
+		}
+		return loginResp, err
+	}
+	if loginPasswordStdin || !stdinIsTerminal() {
+		return nil, validationError("a two-factor code is required, pass it with --otp")
+	}
+
+	for attempt := 1; ; attempt++ {
+		// Backup codes are as secret as the password, so nothing is echoed
+		fmt.Print("authentication or backup code: ")
+		code, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println()
+		if err != nil {
+			return nil, fmt.Errorf("error reading two-factor code: %w", err)
+		}
+
+		loginResp, err := verifyTwoFactorCode(client, string(code))
+		if !errors.Is(err, errInvalidTwoFactorCode) {
+			return loginResp, err
+		}
+		if attempt == twoFactorAttempts {
+			return nil, invalidTwoFactorCodeError()
+		}
+		color.New(color.FgYellow).Println("  invalid code, try again")
+	}
+}
+
+// verifyTwoFactorCode sends a code to the TOTP or backup code endpoint
+func verifyTwoFactorCode(client *http.Client, code string) (*LoginResponse, error) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if code == "" {
+		return nil, validationError("two-factor code is required")
+	}
+
+	verifyURL := authBaseURL + "/auth/two-factor/verify-backup-code"
+	if totpCodeRegex.MatchString(code) {
+		verifyURL = authBaseURL + "/auth/two-factor/verify-totp"
+	}
+
+	jsonData, err := json.Marshal(map[string]string{"code": code})
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare two-factor request: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", verifyURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create two-factor request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "AsyncStatus-CLI/"+Version)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, networkError(fmt.Errorf("failed to connect to AsyncStatus: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, errInvalidTwoFactorCode
+	} else if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("two-factor verification failed: %w", &apiclient.APIError{Status: resp.StatusCode, Body: string(body)})
+	}
+
+	var loginResp LoginResponse
+	if err := json.NewDecoder(resp.Body).Decode(&loginResp); err != nil {
+		return nil, fmt.Errorf("failed to parse two-factor response: %v", err)
+	}
+
+	color.New(color.FgGreen).Println("  ✓ two-factor code accepted")
+	return &loginResp, nil
+}
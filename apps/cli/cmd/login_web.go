@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	apiclient "asyncstatus.com/cli/client"
+	"github.com/fatih/color"
+)
+
+// webLoginTimeout is how long the loopback server waits for the browser
+const webLoginTimeout = 5 * time.Minute
+
+// webLoginPage is shown in the browser once the callback is received
+const webLoginPage = `<!doctype html><html><head><meta charset="utf-8"><title>AsyncStatus CLI</title></head><body style="font-family: system-ui, sans-serif; max-width: 28rem; margin: 4rem auto; padding: 0 1rem;"><h1 style="font-size: 1.25rem;">%s</h1><p>%s</p></body></html>`
+
+// webLoginCallback is the result of the browser redirect to the loopback server
+type webLoginCallback struct {
+	code string
+	err  error
+}
+
+// handleWebLogin logs in through the browser, or with a device code when no
+// browser can be opened or device is set
+func handleWebLogin(ctx context.Context, device bool) error {
+	if authBaseURL == "" {
+		authBaseURL = getAPIURL()
+	}
+
+	api := apiclient.New(authBaseURL, "", apiclient.WithUserAgent("AsyncStatus-CLI/"+Version), apiclient.WithTimeout(getHTTPTimeout()))
+
+	var sessionCookie string
+	var err error
+	if device {
+		sessionCookie, err = deviceLogin(ctx, api)
+	} else {
+		sessionCookie, err = browserLogin(ctx, api)
+	}
+	if err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+
+	email, err := completeSessionLogin(ctx, sessionCookie)
+	if err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+
+	color.New(color.FgGreen).Print("⧗ logged in as ")
+	color.New(color.FgCyan).Println(email)
+	return nil
+}
+
+// browserLogin opens the authorize page in the browser and waits for it to
+// redirect to a loopback server with an authorization code. The state and the
+// PKCE challenge make sure the code came from this login and only this process
+// can exchange it.
+func browserLogin(ctx context.Context, api *apiclient.Client) (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("failed to start local callback server: %w", err)
+	}
+	defer listener.Close()
+
+	state, err := randomURLSafe(32)
+	if err != nil {
+		return "", err
+	}
+	codeVerifier, err := randomURLSafe(32)
+	if err != nil {
+		return "", err
+	}
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr().String())
+	query := url.Values{}
+	query.Set("redirectUri", redirectURI)
+	query.Set("state", state)
+	query.Set("codeChallenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("codeChallengeMethod", "S256")
+	query.Set("clientName", webLoginClientName())
+	authorizeURL := authBaseURL + "/cli/auth/authorize?" + query.Encode()
+
+	callbacks := make(chan webLoginCallback, 1)
+	server := &http.Server{
+		Handler:           webLoginCallbackHandler(state, callbacks),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	if err := openBrowser(authorizeURL); err != nil {
+		color.New(color.FgYellow).Fprintf(os.Stderr, "⧗ could not open a browser (%v), using a device code instead\n", err)
+		return deviceLogin(ctx, api)
+	}
+
+	color.New(color.FgHiBlack).Println("⧗ continue in your browser. If it didn't open, visit:")
+	color.New(color.FgCyan).Printf("  %s\n", authorizeURL)
+	color.New(color.FgHiBlack).Println("  waiting for the browser...")
+
+	ctx, cancel := context.WithTimeout(ctx, webLoginTimeout)
+	defer cancel()
+
+	var callback webLoginCallback
+	select {
+	case callback = <-callbacks:
+	case <-ctx.Done():
+		return "", validationError("timed out waiting for the browser, try asyncstatus login --device")
+	}
+	if callback.err != nil {
+		return "", callback.err
+	}
+
+	color.New(color.FgGreen).Println("  ✓ authorized in the browser")
+
+	sessionCookie, err := api.ExchangeAuthCode(ctx, callback.code, codeVerifier, redirectURI)
+	if err != nil {
+		var apiErr *apiclient.APIError
+		if errors.As(err, &apiErr) && apiErr.Status == http.StatusUnauthorized {
+			return "", authError("the authorization code was rejected, try again")
+		}
+		return "", networkError(fmt.Errorf("failed to exchange authorization code: %w", err))
+	}
+
+	return sessionCookie, nil
+}
+
+// webLoginClientName names this CLI on the approval page of the browser login
+func webLoginClientName() string {
+	name := "AsyncStatus CLI " + Version
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		name += " on " + hostname
+	}
+	return name
+}
+
+// webLoginCallbackHandler receives the browser redirect and sends its result
+// to callbacks. Only the first request with the expected state counts.
+func webLoginCallbackHandler(state string, callbacks chan<- webLoginCallback) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/callback" {
+			http.NotFound(w, r)
+			return
+		}
+
+		query := r.URL.Query()
+		if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(state)) != 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, webLoginPage, "Login failed", "This login link doesn't belong to the running CLI. Run asyncstatus login --web again.")
+			return
+		}
+
+		callback := webLoginCallback{code: query.Get("code")}
+		if query.Get("error") == "access_denied" {
+			callback.err = authError("the login was denied in the browser")
+		} else if reason := query.Get("error"); reason != "" {
+			callback.err = authError("the browser login failed: %s", reason)
+		} else if callback.code == "" {
+			callback.err = authError("the browser login returned no authorization code")
+		}
+
+		if callback.err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, webLoginPage, "Login failed", "Return to your terminal for details.")
+		} else {
+			fmt.Fprintf(w, webLoginPage, "CLI connected", "You can close this page and return to your terminal.")
+		}
+
+		select {
+		case callbacks <- callback:
+		default:
+		}
+	})
+}
+
+// deviceLogin prints a URL and a code to enter on any device with a browser,
+// then polls until the login is approved there
+func deviceLogin(ctx context.Context, api *apiclient.Client) (string, error) {
+	authorization, err := api.StartDeviceAuthorization(ctx)
+	if err != nil {
+		return "", networkError(fmt.Errorf("failed to start device login: %w", err))
+	}
+
+	color.New(color.FgHiBlack).Print("⧗ open ")
+	color.New(color.FgCyan).Print(authorization.VerificationURI)
+	color.New(color.FgHiBlack).Println(" in a browser and enter the code")
+	color.New(color.FgWhite, color.Bold).Printf("  %s\n", authorization.UserCode)
+	color.New(color.FgHiBlack).Println("  waiting for approval...")
+
+	interval := time.Duration(authorization.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(authorization.ExpiresIn)*time.Second)
+	defer cancel()
+
+	for {
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return "", validationError("the code expired before it was approved, run asyncstatus login --device again")
+		}
+
+		status, err := api.PollDeviceAuthorization(ctx, authorization.DeviceCode)
+		if err != nil {
+			var apiErr *apiclient.APIError
+			if errors.As(err, &apiErr) && apiErr.Status == http.StatusUnauthorized {
+				return "", authError("the code expired before it was approved, run asyncstatus login --device again")
+			}
+			return "", networkError(fmt.Errorf("failed to check device login: %w", err))
+		}
+		if status.Status == "approved" && status.SessionCookie != "" {
+			color.New(color.FgGreen).Println("  ✓ approved")
+			return status.SessionCookie, nil
+		}
+	}
+}
+
+// completeSessionLogin gets a JWT token for a session cookie handed over by
+// the server, verifies it and stores both, returning the user's email
+func completeSessionLogin(ctx context.Context, sessionCookie string) (string, error) {
+	authURL, err := url.Parse(authBaseURL)
+	if err != nil {
+		return "", validationError("invalid API URL %q", authBaseURL)
+	}
+	name, value, ok := strings.Cut(sessionCookie, "=")
+	if !ok || name == "" || value == "" {
+		return "", fmt.Errorf("invalid session received from server")
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create cookie jar: %v", err)
+	}
+	jar.SetCookies(authURL, []*http.Cookie{{Name: name, Value: value, Path: "/"}})
+
+	client := &http.Client{
+		Timeout: getHTTPTimeout(),
+		Jar:     jar,
+	}
+
+	color.New(color.FgHiBlack).Println("  retrieving token...")
+	jwtToken, err := getJWTToken(client)
+	if err != nil {
+		return "", fmt.Errorf("authorized but failed to get JWT token: %w", err)
+	}
+
+	// Only store a token that the API signed for itself
+	claims, err := verifyToken(ctx, authBaseURL, jwtToken)
+	if err != nil {
+		return "", fmt.Errorf("refusing to store token: %w", err)
+	}
+	color.New(color.FgHiBlack).Println("  ✓ token signature verified")
+
+	if err := storeCredentials(claims.User.Email, jwtToken, sessionCookieHeader(jar)); err != nil {
+		return "", err
+	}
+	return claims.User.Email, nil
+}
+
+// openBrowser opens a URL in the default browser
+func openBrowser(target string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", target)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			return errors.New("no display")
+		}
+		cmd = exec.Command("xdg-open", target)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// randomURLSafe returns n random bytes encoded as unpadded base64url
+func randomURLSafe(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -17,9 +16,9 @@ var logoutCmd = &cobra.Command{
 
 Examples:
   asyncstatus logout`,
-	Args: cobra.NoArgs,
-	Run: func(cmd *cobra.Command, args []string) {
-		handleLogout()
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleLogout()
 	},
 }
 
@@ -28,23 +27,30 @@ func init() {
 }
 
 // handleLogout processes the logout flow
-func handleLogout() {
+func handleLogout() error {
+	if tokenFromEnv() != "" {
+		return validationError("ASYNCSTATUS_TOKEN is set, unset it to stop using that token")
+	}
+	
 	// Check if user is logged in
 	if !isLoggedIn() {
 		fmt.Println("ℹ️  You are not currently logged in")
-		return
+		return nil
 	}
 	
 	// Get current user info for confirmation
-	email := getCurrentUserEmail()
+	email, err := getCurrentUserEmail()
+	if err != nil {
+		return err
+	}
 	
 	// Perform logout
 	if err := performLogout(); err != nil {
-		fmt.Printf("❌ Logout failed: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("logout failed: %w", err)
 	}
 	
 	fmt.Printf("✅ Successfully logged out %s\n", email)
+	return nil
 }
 
 // performLogout handles the actual logout process
@@ -57,20 +63,19 @@ func performLogout() error {
 		fmt.Println("   Continuing with local logout...")
 	}
 	
-	return clearConfig()
+	return clearProfileCredentials()
 }
 
 // invalidateTokenOnServer attempts to invalidate the JWT token on the server
 func invalidateTokenOnServer() error {
-	// Get the current token - this will exit if not authenticated
-	token := getCurrentToken()
-	
-	// Get API URL from environment or use default
-	apiURL := os.Getenv("ASYNCSTATUS_API_URL")
-	if apiURL == "" {
-		apiURL = "https://api.asyncstatus.com"
+	creds, err := loadCredentials()
+	if err != nil {
+		return err
 	}
 	
+	// Use the API URL of the active profile
+	apiURL := getAPIURL()
+	
 	// Create HTTP client
 	client := &http.Client{
 		Timeout: 10 * time.Second,
@@ -84,9 +89,14 @@ func invalidateTokenOnServer() error {
 	}
 	
 	// Add JWT token to authorization header
-	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Authorization", "Bearer "+creds.Token)
 	req.Header.Set("User-Agent", "AsyncStatus-CLI/"+Version)
 	
+	// Better Auth signs out the session identified by its cookie
+	if creds.SessionCookie != "" {
+		req.Header.Set("Cookie", creds.SessionCookie)
+	}
+	
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to connect to server: %v", err)
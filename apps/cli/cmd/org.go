@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"asyncstatus.com/cli/client"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// orgCmd represents the org command
+var orgCmd = &cobra.Command{
+	Use:     "org",
+	Aliases: []string{"orgs", "organization"},
+	Short:   "List, inspect and switch organizations",
+	Long: `List the organizations you belong to and switch the active one.
+
+Status updates are added to the active organization. Use --org on any command
+to act on another organization once without switching.
+
+Examples:
+  asyncstatus org list
+  asyncstatus org use acme
+  asyncstatus org show
+  asyncstatus --org side-project done "fixed the build"`,
+}
+
+// orgListCmd represents the org list command
+var orgListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List your organizations",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleOrgList(cmd.Context())
+	},
+}
+
+// orgUseCmd represents the org use command
+var orgUseCmd = &cobra.Command{
+	Use:   "use <slug>",
+	Short: "Switch the active organization",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleOrgUse(cmd.Context(), args[0])
+	},
+}
+
+// orgShowCmd represents the org show command
+var orgShowCmd = &cobra.Command{
+	Use:   "show [slug]",
+	Short: "Show an organization's plan and members",
+	Args:  usageArgs(cobra.MaximumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		slug := ""
+		if len(args) > 0 {
+			slug = args[0]
+		}
+		return handleOrgShow(cmd.Context(), slug)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(orgCmd)
+	orgCmd.AddCommand(orgListCmd, orgUseCmd, orgShowCmd)
+}
+
+// currentOrganizationSlug returns the organization commands act on: the one
+// selected with --org or the profile, otherwise the one in the token
+func currentOrganizationSlug() string {
+	if org := getActiveOrganization(); org != "" {
+		return org
+	}
+
+	token, err := getCurrentToken()
+	if err != nil {
+		return ""
+	}
+	claims, err := parseTokenClaims(token)
+	if err != nil {
+		return ""
+	}
+	return claims.User.ActiveOrganizationSlug
+}
+
+// handleOrgList prints the user's organizations, marking the active one
+func handleOrgList(ctx context.Context) error {
+	apiClient, err := newSessionAPIClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	memberships, err := apiClient.ListOrganizations(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(memberships) == 0 {
+		color.New(color.FgHiBlack).Println("⧗ you are not a member of any organization")
+		return nil
+	}
+
+	slugWidth, nameWidth := 0, 0
+	for _, membership := range memberships {
+		slugWidth = max(slugWidth, len(membership.Organization.Slug))
+		nameWidth = max(nameWidth, len(membership.Organization.Name))
+	}
+
+	active := currentOrganizationSlug()
+	for _, membership := range memberships {
+		org := membership.Organization
+		if org.Slug == active {
+			color.New(color.FgGreen).Print("* ")
+			color.New(color.FgWhite, color.Bold).Printf("%-*s", slugWidth, org.Slug)
+		} else {
+			fmt.Print("  ")
+			color.New(color.FgWhite).Printf("%-*s", slugWidth, org.Slug)
+		}
+		color.New(color.FgCyan).Printf("  %-*s", nameWidth, org.Name)
+		color.New(color.FgHiBlack).Printf("  %s\n", membership.Member.Role)
+	}
+
+	return nil
+}
+
+// handleOrgUse makes an organization the active one on the server and in the
+// profile, and fetches a token for it
+func handleOrgUse(ctx context.Context, slug string) error {
+	apiClient, err := newSessionAPIClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	org, err := apiClient.SetActiveOrganization(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	config.activeProfile().Organization = org.Slug
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+
+	// Tokens record the active organization, so get one for the new organization
+	token, tokenErr := apiClient.IssueToken(ctx)
+	if tokenErr == nil {
+		_, tokenErr = verifyToken(ctx, apiClient.BaseURL(), token)
+	}
+	if tokenErr == nil {
+		creds, err := loadCredentials()
+		if err != nil {
+			return err
+		}
+		if err := storeToken(token, creds.SessionCookie); err != nil {
+			return err
+		}
+	}
+
+	color.New(color.FgGreen).Print("⧗ switched to ")
+	color.New(color.FgCyan).Print(org.Name)
+	color.New(color.FgHiBlack).Printf(" (%s)\n", org.Slug)
+	if tokenErr != nil {
+		color.New(color.FgYellow).Printf("  could not refresh token: %v\n", classifyError(tokenErr).message)
+	}
+	return nil
+}
+
+// handleOrgShow prints an organization's plan, member count and the user's role
+func handleOrgShow(ctx context.Context, slug string) error {
+	if slug == "" {
+		slug = currentOrganizationSlug()
+	}
+	if slug == "" {
+		return validationError("no active organization, run: asyncstatus org use <slug>")
+	}
+
+	apiClient, err := newSessionAPIClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	membership, err := apiClient.Organization(ctx, slug)
+	if err != nil {
+		return err
+	}
+	org := membership.Organization
+
+	members, err := apiClient.ListOrganizationMembers(ctx, org.Slug)
+	if err != nil {
+		return err
+	}
+	activeMembers := 0
+	for _, member := range members {
+		if member.ArchivedAt == nil {
+			activeMembers++
+		}
+	}
+
+	color.New(color.FgWhite, color.Bold).Print("⧗ ", org.Name)
+	color.New(color.FgHiBlack).Printf(" (%s)\n", org.Slug)
+
+	color.New(color.FgHiBlack).Print("  plan:    ")
+	color.New(color.FgWhite).Println(describePlan(ctx, apiClient, &org))
+
+	color.New(color.FgHiBlack).Print("  members: ")
+	color.New(color.FgWhite).Println(activeMembers)
+
+	color.New(color.FgHiBlack).Print("  role:    ")
+	color.New(color.FgWhite).Println(membership.Member.Role)
+
+	color.New(color.FgHiBlack).Print("  created: ")
+	color.New(color.FgWhite).Println(org.CreatedAt.Local().Format("January 2, 2006"))
+
+	return nil
+}
+
+// describePlan returns a one-line description of an organization's plan.
+// Billing may be unavailable, in which case the trial recorded on the
+// organization is used.
+func describePlan(ctx context.Context, apiClient *client.Client, org *client.Organization) string {
+	subscription, err := apiClient.OrganizationSubscription(ctx, org.Slug)
+	if err == nil && subscription != nil && subscription.PlanName != nil {
+		source := "subscription"
+		if subscription.PlanSource != nil {
+			source = *subscription.PlanSource
+		}
+		return fmt.Sprintf("%s (%s, %s)", *subscription.PlanName, source, subscription.Status)
+	}
+
+	if org.TrialPlan != nil {
+		status := "trial"
+		if org.TrialStatus != nil {
+			status = "trial, " + *org.TrialStatus
+		}
+		return fmt.Sprintf("%s (%s)", *org.TrialPlan, status)
+	}
+
+	if err != nil {
+		return "unknown"
+	}
+	return "none"
+}
@@ -0,0 +1,412 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"asyncstatus.com/cli/client"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// outboxVersion is the current on-disk format of the outbox file
+const outboxVersion = 1
+
+// OutboxItem is a status update item that could not be sent and waits for `asyncstatus sync`
+type OutboxItem struct {
+	ID        string          `json:"id"`
+	Type      client.ItemType `json:"type"`
+	Message   string          `json:"message"`
+	Date      string          `json:"date"`  // YYYY-MM-DD the item was added for
+	Order     int             `json:"order"` // position in the queue, items are replayed in this order
+	CreatedAt time.Time       `json:"createdAt"`
+	// Organization is the slug selected with --org or the profile when the item
+	// was added, empty for the user's active organization
+	Organization string `json:"organization,omitempty"`
+}
+
+// Outbox is the durable local queue of unsent status update items
+type Outbox struct {
+	Version   int          `json:"version"`
+	NextOrder int          `json:"nextOrder"`
+	Items     []OutboxItem `json:"items"`
+}
+
+// getOutboxPath returns the path to the outbox file of the active profile
+func getOutboxPath() string {
+	return profileOutboxPath(getActiveProfileDir())
+}
+
+// profileOutboxPath returns the path to the outbox file in a profile directory
+func profileOutboxPath(profileDir string) string {
+	return filepath.Join(profileDir, "outbox.json")
+}
+
+// loadOutbox loads the outbox of the active profile, returning an empty one if
+// none exists yet
+func loadOutbox() (*Outbox, error) {
+	return loadOutboxFile(getOutboxPath())
+}
+
+// loadOutboxFile loads an outbox file, returning an empty outbox if none exists yet
+func loadOutboxFile(path string) (*Outbox, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Outbox{Version: outboxVersion, NextOrder: 1}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outbox: %w", err)
+	}
+
+	var outbox Outbox
+	if err := json.Unmarshal(content, &outbox); err != nil {
+		return nil, fmt.Errorf("invalid outbox file format: %v", err)
+	}
+
+	return &outbox, nil
+}
+
+// saveOutbox writes the outbox atomically so a crash never leaves a partial file
+func saveOutbox(outbox *Outbox) error {
+	if err := os.MkdirAll(filepath.Dir(getOutboxPath()), 0700); err != nil {
+		return fmt.Errorf("failed to create profile directory: %v", err)
+	}
+
+	jsonData, err := json.MarshalIndent(outbox, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox: %v", err)
+	}
+
+	return writeFileAtomic(getOutboxPath(), jsonData, 0600)
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory and renames it into place
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := tempFile.Write(data); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	if err := tempFile.Sync(); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	if err := os.Chmod(tempFile.Name(), perm); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+
+	return os.Rename(tempFile.Name(), path)
+}
+
+// newOutboxItemID returns a random ID for an item. The server gets it as the
+// idempotency key of the insert, so an item is only added once however often
+// it's sent.
+func newOutboxItemID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate outbox item id: %v", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// enqueue appends an item to the outbox and persists it
+func (o *Outbox) enqueue(id string, itemType client.ItemType, message, date, organization string) (*OutboxItem, error) {
+	item := OutboxItem{
+		ID:           id,
+		Type:         itemType,
+		Message:      message,
+		Date:         date,
+		Order:        o.NextOrder,
+		CreatedAt:    time.Now(),
+		Organization: organization,
+	}
+	o.Items = append(o.Items, item)
+	o.NextOrder++
+
+	if err := saveOutbox(o); err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// itemsForDate returns the queued items of an organization for a YYYY-MM-DD date in queue order
+func (o *Outbox) itemsForDate(date, organization string) []OutboxItem {
+	var items []OutboxItem
+	for _, item := range o.Items {
+		if item.Date == date && item.Organization == organization {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// isOfflineError reports whether err means the API could not be reached, so the
+// request is worth queueing and retrying later rather than failing outright
+func isOfflineError(err error) bool {
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.Status {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	return classifyError(err).class == errorClassNetwork
+}
+
+// itemDateFlag is the day the add commands add to, today when empty
+var itemDateFlag string
+
+// addItemDateFlag registers the --date flag on a command that adds items
+func addItemDateFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&itemDateFlag, "date", "", "Day to add the item to, e.g. yesterday, friday or 2024-01-15 (default today)")
+}
+
+// sendStatusUpdateItem adds an item to the status update of the --date day,
+// today by default. When the API is unreachable, or earlier items are still
+// waiting in the outbox, the item is queued instead so items always reach the
+// server in the order they were added. It reports whether the item was queued.
+func sendStatusUpdateItem(ctx context.Context, itemType client.ItemType, message string) (bool, error) {
+	apiClient, err := newAPIClient(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	today, err := parseDate("")
+	if err != nil {
+		return false, err
+	}
+	date, err := parseDate(itemDateFlag)
+	if err != nil {
+		return false, err
+	}
+	if date > today {
+		return false, validationError("--date %s is in the future, items can only be added up to today", date)
+	}
+
+	outbox, err := loadOutbox()
+	if err != nil {
+		return false, err
+	}
+
+	// A request that reached the server but whose response was lost is queued,
+	// and the shared ID keeps the replay from adding the item twice
+	id, err := newOutboxItemID()
+	if err != nil {
+		return false, err
+	}
+
+	if len(outbox.Items) > 0 {
+		if _, err := outbox.enqueue(id, itemType, message, date, getActiveOrganization()); err != nil {
+			return false, err
+		}
+		if _, err := syncOutbox(ctx, apiClient, outbox, nil); err != nil {
+			if isOfflineError(err) {
+				return true, nil
+			}
+			return true, err
+		}
+		return false, nil
+	}
+
+	if date == today {
+		var statusUpdate *StatusUpdate
+		statusUpdate, err = apiClient.AddStatusUpdateItemOnce(ctx, itemType, message, id)
+		if err == nil {
+			writeStatusUpdateCache(statusUpdateCacheKey(statusUpdate), statusUpdate)
+			return false, nil
+		}
+	} else {
+		// Past days are only changed through the edit endpoint
+		var statusUpdate *StatusUpdate
+		statusUpdate, err = apiClient.StatusUpdateByDate(ctx, date)
+		if err == nil {
+			err = appendItemsToDate(ctx, apiClient, statusUpdate, date, []OutboxItem{{ID: id, Type: itemType, Message: message}}, true)
+		}
+		if err == nil {
+			return false, nil
+		}
+	}
+	if !isOfflineError(err) {
+		return false, err
+	}
+
+	if _, qerr := outbox.enqueue(id, itemType, message, date, getActiveOrganization()); qerr != nil {
+		return false, fmt.Errorf("%w (and failed to queue offline: %v)", err, qerr)
+	}
+
+	return true, nil
+}
+
+// syncResult describes what happened to one outbox item during a sync
+type syncResult struct {
+	Item      OutboxItem
+	Duplicate bool
+}
+
+// syncOutbox replays queued items in order. Items for today are posted like a
+// regular add; items whose date has rolled over are appended to that day's
+// status update through the edit endpoint. Each item is sent with its ID as
+// the idempotency key, and items the server already has under their ID are
+// dropped as duplicates, which covers requests that reached the server but
+// whose response was lost.
+// Each item is sent to the organization it was added for.
+// Synced items are removed from the outbox as they succeed; on the first
+// failure the remaining items stay queued and the error is returned.
+func syncOutbox(ctx context.Context, apiClient *client.Client, outbox *Outbox, onResult func(syncResult)) ([]syncResult, error) {
+	today, err := parseDate("")
+	if err != nil {
+		return nil, err
+	}
+
+	var results []syncResult
+	report := func(result syncResult) {
+		results = append(results, result)
+		if onResult != nil {
+			onResult(result)
+		}
+	}
+
+	activeOrganization := getActiveOrganization()
+
+	for len(outbox.Items) > 0 {
+		date := outbox.Items[0].Date
+		organization := outbox.Items[0].Organization
+
+		// Take the run of consecutive items for the same date and organization
+		batchSize := 1
+		for batchSize < len(outbox.Items) && outbox.Items[batchSize].Date == date && outbox.Items[batchSize].Organization == organization {
+			batchSize++
+		}
+		batch := outbox.Items[:batchSize]
+
+		// The local cache only holds the organization of this invocation
+		cacheable := organization == activeOrganization
+		orgClient := apiClient.ForOrganization(organization)
+
+		statusUpdate, err := orgClient.StatusUpdateByDate(ctx, date)
+		if err != nil {
+			return results, err
+		}
+
+		var pending []OutboxItem
+		var duplicates []OutboxItem
+		saved := map[string]bool{}
+		if statusUpdate != nil {
+			for _, existing := range statusUpdate.Items {
+				if existing.IdempotencyKey != "" {
+					saved[existing.IdempotencyKey] = true
+				}
+			}
+		}
+		for _, item := range batch {
+			if saved[item.ID] {
+				duplicates = append(duplicates, item)
+				continue
+			}
+			pending = append(pending, item)
+		}
+
+		if date == today {
+			for _, item := range pending {
+				statusUpdate, err := orgClient.AddStatusUpdateItemOnce(ctx, item.Type, item.Message, item.ID)
+				if err != nil {
+					return results, err
+				}
+				if cacheable {
+					writeStatusUpdateCache(statusUpdateCacheKey(statusUpdate), statusUpdate)
+				}
+				outbox.remove(item.ID)
+				if err := saveOutbox(outbox); err != nil {
+					return results, err
+				}
+				report(syncResult{Item: item})
+			}
+		} else if len(pending) > 0 {
+			if err := appendItemsToDate(ctx, orgClient, statusUpdate, date, pending, cacheable); err != nil {
+				return results, err
+			}
+			for _, item := range pending {
+				outbox.remove(item.ID)
+				report(syncResult{Item: item})
+			}
+		}
+
+		for _, item := range duplicates {
+			outbox.remove(item.ID)
+			report(syncResult{Item: item, Duplicate: true})
+		}
+
+		if err := saveOutbox(outbox); err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
+
+// appendItemsToDate appends items to the status update of a past date. Only
+// inserts are sent, so a mood or notes change made meanwhile is kept.
+func appendItemsToDate(ctx context.Context, apiClient *client.Client, statusUpdate *StatusUpdate, date string, items []OutboxItem, cacheable bool) error {
+	request := &client.PatchStatusUpdateRequest{Date: date}
+	order := 0
+	if statusUpdate != nil {
+		for _, existing := range statusUpdate.Items {
+			order = max(order, existing.Order)
+		}
+	}
+	for _, item := range items {
+		order++
+		request.Changes = append(request.Changes, client.ItemChange{
+			Op:             client.ItemChangeInsert,
+			Content:        item.Message,
+			Type:           item.Type,
+			Order:          order,
+			IdempotencyKey: item.ID,
+		})
+	}
+
+	updated, err := apiClient.PatchStatusUpdate(ctx, request)
+	if err != nil {
+		return err
+	}
+	if cacheable {
+		writeStatusUpdateCache(date, updated)
+	}
+
+	return nil
+}
+
+// remove deletes an item from the outbox by ID
+func (o *Outbox) remove(id string) {
+	for i, item := range o.Items {
+		if item.ID == id {
+			o.Items = append(o.Items[:i], o.Items[i+1:]...)
+			return
+		}
+	}
+}
+
+// printQueuedOffline tells the user an item was stored in the outbox instead of sent
+func printQueuedOffline() {
+	color.New(color.FgHiBlack).Println("  ⧗ offline, queued for later")
+	color.New(color.FgHiBlack).Println("  run:", color.New(color.FgWhite).Sprint("asyncstatus sync"), "to send queued items")
+}
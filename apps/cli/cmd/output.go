@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"asyncstatus.com/cli/client"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// outputSchemaVersion is the version of the machine-readable output of show
+// and list. It only changes when fields are renamed, removed or change
+// meaning; fields may be added within a version.
+const outputSchemaVersion = 1
+
+// outputFormats are the values of the output setting
+var outputFormats = []string{"text", "json", "ndjson", "yaml", "csv", "table"}
+
+// csvHeader names the columns of the csv output, one row per item
+var csvHeader = []string{"date", "statusUpdateId", "itemId", "order", "type", "content", "team", "userEmail", "mood", "notes", "updatedAt"}
+
+// getOutputFormat returns the selected output format of show and list
+func getOutputFormat() string {
+	return getSetting("output")
+}
+
+// machineOutput reports whether show and list print a machine-readable format
+func machineOutput() bool {
+	return getOutputFormat() != "text"
+}
+
+// checkOutputSupported rejects an explicit -o on commands that only print text
+func checkOutputSupported(cmd *cobra.Command) error {
+	if getSettingSource("output") != settingSourceFlag || getOutputFormat() == "text" {
+		return nil
+	}
+	if cmd.Parent() == nil || cmd == showCmd || cmd == listCmd {
+		return nil
+	}
+	return validationError("%s doesn't support --output, only show and list do", cmd.CommandPath())
+}
+
+// userOutput is the author of a status update in machine-readable output
+type userOutput struct {
+	ID    string `json:"id" yaml:"id"`
+	Name  string `json:"name" yaml:"name"`
+	Email string `json:"email" yaml:"email"`
+}
+
+// teamOutput is the team of a status update in machine-readable output
+type teamOutput struct {
+	ID   string `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+	Slug string `json:"slug" yaml:"slug"`
+}
+
+// itemOutput is a status update item in machine-readable output
+type itemOutput struct {
+	ID      string          `json:"id" yaml:"id"`
+	Order   int             `json:"order" yaml:"order"`
+	Type    client.ItemType `json:"type" yaml:"type"`
+	Content string          `json:"content" yaml:"content"`
+}
+
+// statusUpdateOutput is a status update in machine-readable output. Its
+// fields are independent of the API and stay stable within a schema version.
+type statusUpdateOutput struct {
+	ID        string       `json:"id" yaml:"id"`
+	Date      string       `json:"date" yaml:"date"`
+	Timezone  string       `json:"timezone" yaml:"timezone"`
+	User      userOutput   `json:"user" yaml:"user"`
+	Team      *teamOutput  `json:"team" yaml:"team"`
+	Mood      *string      `json:"mood" yaml:"mood"`
+	Notes     *string      `json:"notes" yaml:"notes"`
+	Items     []itemOutput `json:"items" yaml:"items"`
+	CreatedAt time.Time    `json:"createdAt" yaml:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt" yaml:"updatedAt"`
+}
+
+// unsyncedItemOutput is an item still waiting in the outbox
+type unsyncedItemOutput struct {
+	Date    string          `json:"date" yaml:"date"`
+	Type    client.ItemType `json:"type" yaml:"type"`
+	Content string          `json:"content" yaml:"content"`
+}
+
+// statusUpdatesDocument is the json and yaml output of show and list
+type statusUpdatesDocument struct {
+	SchemaVersion int                  `json:"schemaVersion" yaml:"schemaVersion"`
+	From          string               `json:"from" yaml:"from"`
+	To            string               `json:"to" yaml:"to"`
+	StatusUpdates []statusUpdateOutput `json:"statusUpdates" yaml:"statusUpdates"`
+	Unsynced      []unsyncedItemOutput `json:"unsynced,omitempty" yaml:"unsynced,omitempty"`
+}
+
+// statusUpdateLine is a line of the ndjson output
+type statusUpdateLine struct {
+	SchemaVersion int `json:"schemaVersion"`
+	statusUpdateOutput
+}
+
+// newStatusUpdateOutput converts a status update for machine-readable output
+func newStatusUpdateOutput(statusUpdate *StatusUpdate) statusUpdateOutput {
+	out := statusUpdateOutput{
+		ID:       statusUpdate.ID,
+		Date:     statusUpdateDate(statusUpdate),
+		Timezone: statusUpdate.Timezone,
+		User: userOutput{
+			ID:    statusUpdate.Member.User.ID,
+			Name:  statusUpdate.Member.User.Name,
+			Email: statusUpdate.Member.User.Email,
+		},
+		Mood:      statusUpdate.Mood,
+		Notes:     statusUpdate.Notes,
+		Items:     []itemOutput{},
+		CreatedAt: statusUpdate.CreatedAt,
+		UpdatedAt: statusUpdate.UpdatedAt,
+	}
+	if statusUpdate.Team != nil {
+		out.Team = &teamOutput{ID: statusUpdate.Team.ID, Name: statusUpdate.Team.Name, Slug: statusUpdate.Team.Slug}
+	}
+	for _, item := range statusUpdate.Items {
+		out.Items = append(out.Items, itemOutput{ID: item.ID, Order: item.Order, Type: item.Type(), Content: item.Content})
+	}
+	return out
+}
+
+// statusUpdateWriter prints status updates in a machine-readable format.
+// ndjson and csv are written as updates arrive; json, yaml and table are
+// written by flush.
+type statusUpdateWriter struct {
+	format   string
+	out      io.Writer
+	document statusUpdatesDocument
+	csv      *csv.Writer
+}
+
+// newStatusUpdateWriter starts the output of the status updates of the
+// YYYY-MM-DD days from through to
+func newStatusUpdateWriter(format, from, to string) *statusUpdateWriter {
+	w := &statusUpdateWriter{
+		format: format,
+		out:    os.Stdout,
+		document: statusUpdatesDocument{
+			SchemaVersion: outputSchemaVersion,
+			From:          from,
+			To:            to,
+			StatusUpdates: []statusUpdateOutput{},
+		},
+	}
+	if format == "csv" {
+		w.csv = csv.NewWriter(w.out)
+		_ = w.csv.Write(csvHeader)
+	}
+	return w
+}
+
+// write adds a status update to the output
+func (w *statusUpdateWriter) write(statusUpdate *StatusUpdate) error {
+	out := newStatusUpdateOutput(statusUpdate)
+
+	switch w.format {
+	case "ndjson":
+		data, err := json.Marshal(statusUpdateLine{SchemaVersion: outputSchemaVersion, statusUpdateOutput: out})
+		if err != nil {
+			return fmt.Errorf("failed to encode output: %v", err)
+		}
+		if _, err := fmt.Fprintln(w.out, string(data)); err != nil {
+			return fmt.Errorf("failed to write output: %v", err)
+		}
+		return nil
+	case "csv":
+		for _, item := range out.Items {
+			team := ""
+			if out.Team != nil {
+				team = out.Team.Name
+			}
+			_ = w.csv.Write([]string{
+				out.Date, out.ID, item.ID, strconv.Itoa(item.Order), string(item.Type), item.Content,
+				team, out.User.Email, derefString(out.Mood), derefString(out.Notes), out.UpdatedAt.Format(time.RFC3339),
+			})
+		}
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return fmt.Errorf("failed to write output: %v", err)
+		}
+		return nil
+	}
+
+	w.document.StatusUpdates = append(w.document.StatusUpdates, out)
+	return nil
+}
+
+// addUnsynced adds items still waiting in the outbox, which only the json
+// and yaml documents include
+func (w *statusUpdateWriter) addUnsynced(items []OutboxItem) {
+	for _, item := range items {
+		w.document.Unsynced = append(w.document.Unsynced, unsyncedItemOutput{Date: item.Date, Type: item.Type, Content: item.Message})
+	}
+}
+
+// flush writes the formats that need every status update
+func (w *statusUpdateWriter) flush() error {
+	var err error
+	switch w.format {
+	case "json":
+		encoder := json.NewEncoder(w.out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(w.document)
+	case "yaml":
+		encoder := yaml.NewEncoder(w.out)
+		encoder.SetIndent(2)
+		if err = encoder.Encode(w.document); err == nil {
+			err = encoder.Close()
+		}
+	case "table":
+		table := tabwriter.NewWriter(w.out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "DATE\tTYPE\tCONTENT\tTEAM")
+		for _, statusUpdate := range w.document.StatusUpdates {
+			team := "-"
+			if statusUpdate.Team != nil {
+				team = statusUpdate.Team.Name
+			}
+			for _, item := range statusUpdate.Items {
+				fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", statusUpdate.Date, item.Type, strings.ReplaceAll(item.Content, "\t", " "), team)
+			}
+		}
+		err = table.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write output: %v", err)
+	}
+	return nil
+}
+
+// derefString returns the value of an optional string, or "" if it is nil
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
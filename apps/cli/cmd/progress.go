@@ -1,11 +1,9 @@
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io"
+	"context"
 
+	"asyncstatus.com/cli/client"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -18,57 +16,31 @@ var progressCmd = &cobra.Command{
 
 Examples:
   asyncstatus progress "working on the user dashboard"
-  asyncstatus progress "implementing OAuth integration"`,
-	Args: cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := handleProgressStatus(args[0]); err != nil {
-			color.New(color.FgRed).Printf("⧗ failed: %v\n", err)
-			color.New(color.FgHiBlack).Println("  run:", color.New(color.FgWhite).Sprint("asyncstatus login"), "first")
-		}
+  asyncstatus progress "implementing OAuth integration"
+  asyncstatus progress --date yesterday "forgot to log this"`,
+	Args: usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleProgressStatus(cmd.Context(), args[0])
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(progressCmd)
+	addItemDateFlag(progressCmd)
 }
 
 // handleProgressStatus processes adding a progress status update
-func handleProgressStatus(message string) error {
+func handleProgressStatus(ctx context.Context, message string) error {
 	color.New(color.FgYellow).Print("⧗ progress: ")
 	color.New(color.FgWhite).Println(message)
 	
-	// Create the request payload
-	payload := StatusUpdateRequest{
-		Type:    "progress",
-		Message: message,
-	}
-	
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to prepare request: %v", err)
-	}
-	
-	// Make authenticated request to the new CLI endpoint
-	endpoint := "/cli/status-updates"
-	client, req, err := makeAuthenticatedJSONRequest("POST", endpoint)
+	queued, err := sendStatusUpdateItem(ctx, client.ItemTypeProgress, message)
 	if err != nil {
 		return err
 	}
-	
-	// Set request body
-	req.Body = io.NopCloser(bytes.NewBuffer(jsonData))
-	
-	// Send request
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %v", err)
-	}
-	defer resp.Body.Close()
-	
-	// Check response
-	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("server error (status %d): %s", resp.StatusCode, string(body))
+	if queued {
+		printQueuedOffline()
+		return nil
 	}
 	
 	color.New(color.FgYellow).Println("  → saved")
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+var (
+	renderAsFlag string
+	copyFlag     bool
+)
+
+// chatFormat describes the markup of a chat platform or document format
+// that show --as renders status updates in
+type chatFormat struct {
+	// heading, section, item, label and paragraph are format strings for the
+	// date, a section title, an item, a field label and a line of text
+	heading   string
+	section   string
+	item      string
+	label     string
+	paragraph string
+	// listOpen and listClose wrap the items of a section
+	listOpen  string
+	listClose string
+	// separator joins the blocks of a status update and the updates of a range
+	separator string
+	escape    func(string) string
+	notes     func(string) string
+	html      bool
+}
+
+// chatFormats are the formats show --as accepts
+var chatFormats = map[string]chatFormat{
+	// Slack mrkdwn only needs &, < and > escaped
+	"slack": {
+		heading:   "*%s*",
+		section:   "*%s*",
+		item:      "• %s",
+		label:     "*%s:*",
+		paragraph: "%s",
+		separator: "\n\n",
+		escape:    strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace,
+		notes:     quoteLines("> "),
+	},
+	"discord": {
+		heading:   "**%s**",
+		section:   "**%s**",
+		item:      "- %s",
+		label:     "**%s:**",
+		paragraph: "%s",
+		separator: "\n\n",
+		escape:    escapeDiscord,
+		notes:     quoteLines("> "),
+	},
+	"markdown": {
+		heading:   "## %s",
+		section:   "### %s",
+		item:      "- %s",
+		label:     "**%s:**",
+		paragraph: "%s",
+		separator: "\n\n",
+		escape:    markdownEscaper.Replace,
+		notes:     quoteLines("> "),
+	},
+	// Teams renders pasted HTML, but only a small set of tags
+	"teams": {
+		heading:   "<p><b>%s</b></p>",
+		section:   "<p><b>%s</b></p>",
+		item:      "<li>%s</li>",
+		label:     "<b>%s:</b>",
+		paragraph: "<p>%s</p>",
+		listOpen:  "<ul>\n",
+		listClose: "\n</ul>",
+		separator: "\n",
+		escape:    html.EscapeString,
+		notes:     func(s string) string { return "<p>" + strings.ReplaceAll(s, "\n", "<br>") + "</p>" },
+		html:      true,
+	},
+	"html": {
+		heading:   "<h2>%s</h2>",
+		section:   "<h3>%s</h3>",
+		item:      "<li>%s</li>",
+		label:     "<strong>%s:</strong>",
+		paragraph: "<p>%s</p>",
+		listOpen:  "<ul>\n",
+		listClose: "\n</ul>",
+		separator: "\n",
+		escape:    html.EscapeString,
+		notes:     func(s string) string { return "<blockquote>" + strings.ReplaceAll(s, "\n", "<br>") + "</blockquote>" },
+		html:      true,
+	},
+}
+
+// markdownEscaper backslash-escapes the characters markdown and Discord read
+// as formatting, so item text can't break the surrounding markup
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "~", `\~`, "|", `\|`, "[", `\[`, "]", `\]`,
+)
+
+// discordMentionReplacer breaks mentions with a zero-width space, so pasting
+// an update doesn't ping the channel or anyone in it
+var discordMentionReplacer = strings.NewReplacer("@everyone", "@\u200beveryone", "@here", "@\u200bhere", "<@", "<\u200b@")
+
+// escapeDiscord escapes markdown and neutralises mentions for Discord
+func escapeDiscord(s string) string {
+	return discordMentionReplacer.Replace(markdownEscaper.Replace(s))
+}
+
+// quoteLines returns a function prefixing every line of a text
+func quoteLines(prefix string) func(string) string {
+	return func(s string) string {
+		return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+	}
+}
+
+// chatFormatNames returns the names of the --as formats, sorted
+func chatFormatNames() []string {
+	names := make([]string, 0, len(chatFormats))
+	for name := range chatFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getChatFormat returns the format selected with --as, or nil if it is not
+// set, checking it against the other output flags
+func getChatFormat() (*chatFormat, error) {
+	if renderAsFlag == "" {
+		if copyFlag {
+			return nil, validationError("--copy needs --as, e.g. --as slack --copy")
+		}
+		return nil, nil
+	}
+
+	format, ok := chatFormats[renderAsFlag]
+	if !ok {
+		return nil, validationError("invalid --as %q, expected one of: %s", renderAsFlag, strings.Join(chatFormatNames(), ", "))
+	}
+	if formatFlag != "" || templateFlag != "" {
+		return nil, validationError("--as cannot be combined with --format or --template")
+	}
+	if machineOutput() {
+		return nil, validationError("--as cannot be combined with --output %s", getOutputFormat())
+	}
+	return &format, nil
+}
+
+// groupStatusUpdateItems splits the items of a status update by type
+func groupStatusUpdateItems(statusUpdate *StatusUpdate) (completed, progress, blockers []StatusUpdateItem) {
+	for _, item := range statusUpdate.Items {
+		if item.IsBlocker {
+			blockers = append(blockers, item)
+		} else if item.IsInProgress {
+			progress = append(progress, item)
+		} else {
+			completed = append(completed, item)
+		}
+	}
+	return completed, progress, blockers
+}
+
+// render returns a status update in the markup of the format
+func (f *chatFormat) render(statusUpdate *StatusUpdate) string {
+	blocks := []string{fmt.Sprintf(f.heading, f.escape(formatDate(statusUpdateDay(statusUpdate))))}
+
+	completed, progress, blockers := groupStatusUpdateItems(statusUpdate)
+	sections := []struct {
+		title string
+		items []StatusUpdateItem
+	}{
+		{"✅ Completed", completed},
+		{"🔄 In progress", progress},
+		{"🚧 Blocked", blockers},
+	}
+	for _, section := range sections {
+		if len(section.items) == 0 {
+			continue
+		}
+		lines := make([]string, 0, len(section.items))
+		for _, item := range section.items {
+			lines = append(lines, fmt.Sprintf(f.item, f.escape(item.Content)))
+		}
+		blocks = append(blocks, fmt.Sprintf(f.section, section.title)+"\n"+f.listOpen+strings.Join(lines, "\n")+f.listClose)
+	}
+
+	if statusUpdate.Mood != nil && strings.TrimSpace(*statusUpdate.Mood) != "" {
+		blocks = append(blocks, fmt.Sprintf(f.paragraph, fmt.Sprintf(f.label, "Mood")+" "+f.escape(strings.TrimSpace(*statusUpdate.Mood))))
+	}
+	if statusUpdate.Notes != nil && strings.TrimSpace(*statusUpdate.Notes) != "" {
+		blocks = append(blocks, fmt.Sprintf(f.paragraph, fmt.Sprintf(f.label, "Notes"))+"\n"+f.notes(f.escape(strings.TrimSpace(*statusUpdate.Notes))))
+	}
+
+	return strings.Join(blocks, f.separator)
+}
+
+// handleShowAs prints or copies the status updates of one or more days in the
+// markup of a chat platform
+func handleShowAs(ctx context.Context, dates []string, policy cachePolicy, format *chatFormat) error {
+	var rendered []string
+	for _, date := range dates {
+		statusUpdate, err := getSettledStatusUpdate(ctx, date, policy)
+		if err != nil {
+			return err
+		}
+		if statusUpdate != nil {
+			rendered = append(rendered, format.render(statusUpdate))
+		}
+	}
+	if len(rendered) == 0 {
+		if len(dates) == 1 {
+			return validationError("no updates found for %s", formatDateForDisplay(dates[0]))
+		}
+		return validationError("no updates found from %s to %s", formatDateForDisplay(dates[0]), formatDateForDisplay(dates[len(dates)-1]))
+	}
+	text := strings.Join(rendered, format.separator) + "\n"
+
+	if !copyFlag {
+		fmt.Print(text)
+		return nil
+	}
+
+	tool, err := copyToClipboard(text, format.html)
+	if err != nil {
+		return err
+	}
+	color.New(color.FgGreen).Printf("✓ copied %d update(s) as %s to the clipboard", len(rendered), renderAsFlag)
+	color.New(color.FgHiBlack).Printf(" (%s)\n", tool)
+	return nil
+}
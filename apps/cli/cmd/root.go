@@ -1,9 +1,10 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"strings"
 
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
@@ -36,50 +37,100 @@ Examples:
   asyncstatus list                      # List today's status updates
   asyncstatus list 7                    # List status updates from past 7 days
   asyncstatus undo                      # Remove the previous status update
+  asyncstatus org list                  # List your organizations
+  asyncstatus config list               # Show settings and where they come from
   
  Links:
   - https://asyncstatus.com
   - https://github.com/asyncstatus/asyncstatus
   - https://github.com/asyncstatus/asyncstatus/issues
   - https://github.com/asyncstatus/asyncstatus/releases`,
-	Args:    cobra.MaximumNArgs(1),
+	Args:          usageArgs(cobra.MaximumNArgs(1)),
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if errorFormat != "text" && errorFormat != "json" {
+			errorFormat = "text"
+			return validationError("invalid --error-format %q, expected text or json", cmd.Flag("error-format").Value)
+		}
+		if orgFlag != "" && !profileNameRegex.MatchString(orgFlag) {
+			return validationError("invalid --org %q, expected an organization slug", orgFlag)
+		}
+		if profileFlag != "" && !profileNameRegex.MatchString(profileFlag) {
+			return validationError("invalid --profile %q, use letters, digits, '.', '_' and '-'", profileFlag)
+		}
+		// The config commands must work with invalid settings to fix them
+		if !isConfigCommand(cmd) {
+			if err := validateSettings(); err != nil {
+				return err
+			}
+			if err := checkOutputSupported(cmd); err != nil {
+				return err
+			}
+		}
+		applyColorSetting()
+		return nil
+	},
 	PreRun: func(cmd *cobra.Command, args []string) {
 		// Handle --version flag by calling our custom version handler
 		if versionFlag, _ := cmd.Flags().GetBool("version"); versionFlag {
 			handleVersion()
-			os.Exit(0)
+			os.Exit(exitOK)
 		}
 	},
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// If no subcommand is provided but there's an argument,
-		// treat it as a "done" status update
+		// treat it as a status update of the default-type setting
 		if len(args) == 1 {
-			if err := handleDoneStatus(args[0]); err != nil {
-				color.New(color.FgRed).Printf("⧗ failed: %v\n", err)
-				color.New(color.FgHiBlack).Println("  run:", color.New(color.FgWhite).Sprint("asyncstatus login"), "first")
-			}
-		} else {
-			// Show current status update when no arguments provided
-			if err := handleShowStatus(""); err != nil {
-				color.New(color.FgRed).Printf("⧗ failed: %v\n", err)
-				color.New(color.FgHiBlack).Println("  run:", color.New(color.FgWhite).Sprint("asyncstatus login"), "first")
+			switch getSetting("default-type") {
+			case "progress":
+				return handleProgressStatus(cmd.Context(), args[0])
+			case "blocker":
+				return handleBlockerStatus(cmd.Context(), args[0])
 			}
+			return handleDoneStatus(cmd.Context(), args[0])
 		}
+		
+		// Show current status update when no arguments provided
+		return handleShowStatus(cmd.Context(), "")
 	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Failures are reported once here, and the process exits with the code for the failure class.
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(reportError(err))
 	}
 }
 
 func init() {
 	// Custom version flag that shows build info and checks for updates
 	rootCmd.Flags().BoolP("version", "v", false, "version for asyncstatus")
+	addCacheFlags(rootCmd)
+	addItemDateFlag(rootCmd)
+	rootCmd.PersistentFlags().StringVar(&errorFormat, "error-format", "text", "Error output format (text, json)")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Profile to use (overrides ASYNCSTATUS_PROFILE and the current context)")
+	rootCmd.PersistentFlags().StringVar(&orgFlag, "org", "", "Organization slug to use for this command instead of the active one")
+	rootCmd.PersistentFlags().StringVar(&colorFlag, "color", "", "Colored output: auto, always or never (overrides the color setting)")
+	rootCmd.PersistentFlags().StringVar(&httpTimeoutFlag, "http-timeout", "", "Timeout of API requests, e.g. 1m (overrides the http-timeout setting)")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", "", "Output of show and list: text, json, ndjson, yaml, csv or table (overrides the output setting)")
+	rootCmd.PersistentFlags().StringVar(&tzFlag, "tz", "", "Time zone for dates and times: account, local or an IANA zone (overrides the timezone setting)")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		hint := fmt.Sprintf("run: %s --help", cmd.CommandPath())
+		// Offsets like -3d look like flags to the parser
+		if _, arg, ok := strings.Cut(err.Error(), " in "); ok && offsetRegex.MatchString(arg) {
+			hint = fmt.Sprintf("to pass a date offset, put it after --: %s -- %s", cmd.CommandPath(), arg)
+		}
+		return &cliError{
+			class:   errorClassValidation,
+			message: err.Error(),
+			hint:    hint,
+			err:     err,
+		}
+	})
 }
 
 // Note: handleDoneStatus is now implemented in done.go
\ No newline at end of file
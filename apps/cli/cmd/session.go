@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"asyncstatus.com/cli/client"
+	"github.com/fatih/color"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// tokenRefreshAge is the token age after which it is replaced using the
+	// session cookie. Refreshing also keeps the web session from expiring.
+	tokenRefreshAge = 24 * time.Hour
+	// tokenExpiryWarning is how long before expiry the user is warned when
+	// the token can't be refreshed
+	tokenExpiryWarning = 3 * 24 * time.Hour
+)
+
+// tokenUser is the user recorded in a JWT token when it was issued
+type tokenUser struct {
+	ID                     string `json:"id"`
+	Email                  string `json:"email"`
+	Name                   string `json:"name"`
+	ActiveOrganizationSlug string `json:"activeOrganizationSlug"`
+	Timezone               string `json:"timezone"`
+}
+
+// tokenClaims are the claims of the JWT tokens issued by the API
+type tokenClaims struct {
+	User tokenUser `json:"user"`
+	jwt.RegisteredClaims
+}
+
+// parseTokenClaims decodes the claims of a JWT token without checking its signature
+func parseTokenClaims(token string) (*tokenClaims, error) {
+	var claims tokenClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(token, &claims); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	return &claims, nil
+}
+
+// getFreshToken returns the token of the active profile, first replacing it
+// through the session cookie when it is old or about to expire. Without a way
+// to refresh, it warns before the token expires and fails once it has.
+func getFreshToken(ctx context.Context) (string, error) {
+	token, err := getCurrentToken()
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := parseTokenClaims(token)
+	if err != nil {
+		// Not a token we understand, let the server decide
+		return token, nil
+	}
+
+	var remaining time.Duration
+	if claims.ExpiresAt != nil {
+		remaining = time.Until(claims.ExpiresAt.Time)
+	}
+	expiring := claims.ExpiresAt != nil && remaining < tokenExpiryWarning
+	old := claims.IssuedAt != nil && time.Since(claims.IssuedAt.Time) > tokenRefreshAge
+
+	if expiring || old {
+		refreshed, err := refreshStoredToken(ctx)
+		if err == nil {
+			return refreshed, nil
+		}
+		// An old token keeps working, one about to expire needs the refresh.
+		// A token from the environment can't be refreshed anyway.
+		if expiring && tokenFromEnv() == "" {
+			warnRefreshFailed(err)
+		}
+	}
+
+	if claims.ExpiresAt == nil || !expiring {
+		return token, nil
+	}
+	if remaining <= 0 {
+		expiredAt := claims.ExpiresAt.Local().Format("January 2, 2006 at 15:04")
+		if tokenFromEnv() != "" {
+			return "", authError("the token in ASYNCSTATUS_TOKEN expired on %s", expiredAt)
+		}
+		return "", authError("your session expired on %s", expiredAt)
+	}
+
+	color.New(color.FgYellow).Fprintf(os.Stderr, "⧗ your session expires in %s, %s\n", formatRemaining(remaining), authHint())
+	return token, nil
+}
+
+// warnRefreshFailed tells why the token couldn't be refreshed
+func warnRefreshFailed(err error) {
+	classified := classifyError(err)
+	var reason string
+	switch {
+	case classified.class == errorClassNetwork:
+		reason = fmt.Sprintf("%s is unreachable", getAPIURL())
+	case classified.status == http.StatusUnauthorized || classified.status == http.StatusForbidden:
+		reason = "the server rejected the stored session"
+	case classified.class == errorClassAuth:
+		reason = "no login session is stored"
+	default:
+		reason = classified.message
+	}
+	color.New(color.FgYellow).Fprintf(os.Stderr, "⧗ couldn't refresh the token: %s\n", reason)
+}
+
+// refreshStoredToken gets a new token for the active profile with its
+// session cookie and saves it
+func refreshStoredToken(ctx context.Context) (string, error) {
+	creds, err := loadCredentials()
+	if err != nil {
+		return "", err
+	}
+	if creds.SessionCookie == "" {
+		return "", authError("no session stored to refresh the token")
+	}
+
+	token, err := client.New(
+		getAPIURL(),
+		"",
+		client.WithUserAgent("AsyncStatus-CLI/"+Version),
+		client.WithSessionCookie(creds.SessionCookie),
+		client.WithTimeout(10*time.Second),
+	).IssueToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	if _, err := verifyToken(ctx, getAPIURL(), token); err != nil {
+		return "", err
+	}
+
+	return token, storeToken(token, creds.SessionCookie)
+}
+
+// storeToken saves a new token for the active profile
+func storeToken(token, sessionCookie string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if config.activeProfile() == nil {
+		return authError("not authenticated")
+	}
+
+	return saveCredentials(config, config.activeProfileName(), &storedCredentials{Token: token, SessionCookie: sessionCookie})
+}
+
+// formatRemaining formats a duration until expiry for humans
+func formatRemaining(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%d days", int(d.Hours()/24))
+	case d >= 2*time.Hour:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d minutes", max(1, int(d.Minutes())))
+	}
+}
@@ -0,0 +1,391 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"asyncstatus.com/cli/client"
+	"github.com/fatih/color"
+)
+
+// Sources of a setting value, from highest to lowest precedence
+const (
+	settingSourceFlag    = "flag"
+	settingSourceEnv     = "env"
+	settingSourceProfile = "profile"
+	settingSourceGlobal  = "global"
+	settingSourceDefault = "default"
+)
+
+// datePresets are the named values of the date-format setting
+var datePresets = map[string]string{
+	"long":  "Monday, January 2, 2006",
+	"short": "Mon, Jan 2",
+	"iso":   "2006-01-02",
+}
+
+// timePresets are the named values of the time-format setting
+var timePresets = map[string]string{
+	"24h": "15:04",
+	"12h": "3:04 PM",
+}
+
+// setting describes a configurable value
+type setting struct {
+	key          string
+	description  string
+	defaultValue string
+	// flag is the global flag overriding the setting, if any, and flagValue
+	// holds its value
+	flag      string
+	flagValue *string
+	validate  func(value string) error
+}
+
+var (
+	colorFlag       string
+	httpTimeoutFlag string
+	tzFlag          string
+	outputFlag      string
+)
+
+// envVar returns the environment variable overriding the setting
+func (s *setting) envVar() string {
+	return "ASYNCSTATUS_" + strings.ToUpper(strings.ReplaceAll(s.key, "-", "_"))
+}
+
+// settingsSchema lists every setting in the order config list prints them
+var settingsSchema = []*setting{
+	{
+		key:          "api-url",
+		description:  "API server URL; a profile keeps the one it logged in to",
+		defaultValue: client.DefaultBaseURL,
+		validate:     validateURLSetting,
+	},
+	{
+		key:         "editor",
+		description: "Editor command for asyncstatus edit, before GIT_EDITOR, VISUAL and EDITOR",
+		validate: func(value string) error {
+			if strings.TrimSpace(value) == "" {
+				return fmt.Errorf("expected an editor command")
+			}
+			return nil
+		},
+	},
+	{
+		key:          "http-timeout",
+		description:  "Timeout of requests to the API",
+		defaultValue: "30s",
+		flag:         "http-timeout",
+		flagValue:    &httpTimeoutFlag,
+		validate: func(value string) error {
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout <= 0 {
+				return fmt.Errorf("expected a positive duration such as 30s or 2m")
+			}
+			return nil
+		},
+	},
+	{
+		key:          "default-type",
+		description:  "Item type added by asyncstatus \"message\"",
+		defaultValue: "done",
+		validate:     oneOfSetting("done", "progress", "blocker"),
+	},
+	{
+		key:          "color",
+		description:  "Colored output; auto disables it when not writing to a terminal or NO_COLOR is set",
+		defaultValue: "auto",
+		flag:         "color",
+		flagValue:    &colorFlag,
+		validate:     oneOfSetting("auto", "always", "never"),
+	},
+	{
+		key:          "date-format",
+		description:  "Date display format: long, short, iso or a Go layout like \"Jan 2, 2006\"",
+		defaultValue: "long",
+		validate:     layoutSetting(datePresets),
+	},
+	{
+		key:          "time-format",
+		description:  "Time display format: 24h, 12h or a Go layout like \"15:04:05\"",
+		defaultValue: "24h",
+		validate:     layoutSetting(timePresets),
+	},
+	{
+		key:          "timezone",
+		description:  "Zone of today, yesterday and displayed times: account, local or an IANA zone",
+		defaultValue: "account",
+		flag:         "tz",
+		flagValue:    &tzFlag,
+		validate:     validateTimezoneSetting,
+	},
+	{
+		key:          "output",
+		description:  "Output of show and list: text, json, ndjson, yaml, csv or table",
+		defaultValue: "text",
+		flag:         "output",
+		flagValue:    &outputFlag,
+		validate:     oneOfSetting(outputFormats...),
+	},
+}
+
+// lookupSetting returns the setting with the given key
+func lookupSetting(key string) (*setting, error) {
+	for _, s := range settingsSchema {
+		if s.key == key {
+			return s, nil
+		}
+	}
+
+	keys := make([]string, 0, len(settingsSchema))
+	for _, s := range settingsSchema {
+		keys = append(keys, s.key)
+	}
+	return nil, validationError("unknown setting %q, expected one of: %s", key, strings.Join(keys, ", "))
+}
+
+// validateURLSetting accepts absolute http and https URLs
+func validateURLSetting(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("expected an http or https URL")
+	}
+	return nil
+}
+
+// oneOfSetting accepts one of the given values
+func oneOfSetting(values ...string) func(string) error {
+	return func(value string) error {
+		for _, allowed := range values {
+			if value == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected one of: %s", strings.Join(values, ", "))
+	}
+}
+
+// layoutSetting accepts a preset name or a Go time layout
+func layoutSetting(presets map[string]string) func(string) error {
+	return func(value string) error {
+		if _, ok := presets[value]; ok {
+			return nil
+		}
+		// A layout without any date or time element formats to itself
+		reference := time.Date(2001, time.November, 23, 21, 10, 9, 0, time.UTC)
+		if strings.TrimSpace(value) == "" || reference.Format(value) == value {
+			return fmt.Errorf("expected a preset or a Go layout using the reference time Mon Jan 2 15:04:05 2006")
+		}
+		return nil
+	}
+}
+
+// validateSettingValue checks a value against the schema of its setting
+func validateSettingValue(key, value string) error {
+	s, err := lookupSetting(key)
+	if err != nil {
+		return err
+	}
+	if err := s.validate(value); err != nil {
+		return validationError("invalid value %q for %s: %v", value, key, err)
+	}
+	return nil
+}
+
+// settingValue returns a value stored in a profile. The API URL predates
+// profile settings and keeps its own field.
+func (p *Profile) settingValue(key string) string {
+	if key == "api-url" {
+		return p.APIURL
+	}
+	return p.Settings[key]
+}
+
+// setSettingValue stores a value in a profile, removing it if value is empty
+func (p *Profile) setSettingValue(key, value string) {
+	if key == "api-url" {
+		p.APIURL = value
+		return
+	}
+	if value == "" {
+		delete(p.Settings, key)
+		return
+	}
+	if p.Settings == nil {
+		p.Settings = map[string]string{}
+	}
+	p.Settings[key] = value
+}
+
+// setSettingValue stores a value in the global settings, removing it if value is empty
+func (c *Config) setSettingValue(key, value string) {
+	if value == "" {
+		delete(c.Settings, key)
+		return
+	}
+	if c.Settings == nil {
+		c.Settings = map[string]string{}
+	}
+	c.Settings[key] = value
+}
+
+// resolvedSetting is the effective value of a setting and where it came from
+type resolvedSetting struct {
+	value  string
+	source string
+	// origin names the flag, variable or file the value was read from
+	origin string
+}
+
+// resolvedSettings caches the settings of this run, since they are read for
+// every printed date
+var resolvedSettings map[string]resolvedSetting
+
+// resolveSetting returns the effective value of a setting, taking the first
+// of: its flag, its environment variable, the active profile, the global
+// settings and the default
+func resolveSetting(s *setting, config *Config) resolvedSetting {
+	if s.flagValue != nil && *s.flagValue != "" {
+		return resolvedSetting{value: *s.flagValue, source: settingSourceFlag, origin: "--" + s.flag}
+	}
+	if value := os.Getenv(s.envVar()); value != "" {
+		return resolvedSetting{value: value, source: settingSourceEnv, origin: s.envVar()}
+	}
+	if config != nil {
+		if profile := config.activeProfile(); profile != nil {
+			if value := profile.settingValue(s.key); value != "" {
+				return resolvedSetting{value: value, source: settingSourceProfile, origin: config.activeProfileName()}
+			}
+		}
+		if value := config.Settings[s.key]; value != "" {
+			return resolvedSetting{value: value, source: settingSourceGlobal, origin: getConfigPath()}
+		}
+	}
+	return resolvedSetting{value: s.defaultValue, source: settingSourceDefault}
+}
+
+// getSetting returns the effective value of a setting. Invalid values are
+// reported by validateSettings before commands run, so they fall back to the
+// default here.
+func getSetting(key string) string {
+	if resolvedSettings == nil {
+		config, err := loadConfig()
+		if err != nil {
+			config = nil
+		}
+		resolvedSettings = map[string]resolvedSetting{}
+		for _, s := range settingsSchema {
+			resolvedSettings[s.key] = resolveSetting(s, config)
+		}
+	}
+
+	s, err := lookupSetting(key)
+	if err != nil {
+		return ""
+	}
+	resolved := resolvedSettings[key]
+	if s.validate(resolved.value) != nil {
+		return s.defaultValue
+	}
+	return resolved.value
+}
+
+// getSettingSource returns where the effective value of a setting came from
+func getSettingSource(key string) string {
+	getSetting(key)
+	if resolved, ok := resolvedSettings[key]; ok {
+		return resolved.source
+	}
+	return settingSourceDefault
+}
+
+// validateSettings checks the effective value of every setting, naming where
+// an invalid one was set
+func validateSettings() error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range settingsSchema {
+		resolved := resolveSetting(s, config)
+		if resolved.source == settingSourceDefault {
+			continue
+		}
+		if err := s.validate(resolved.value); err != nil {
+			hint := fmt.Sprintf("run: asyncstatus config set %s <value>, or asyncstatus config unset %s", s.key, s.key)
+			switch resolved.source {
+			case settingSourceFlag:
+				hint = "run: asyncstatus --help"
+			case settingSourceEnv:
+				hint = fmt.Sprintf("fix or unset %s", resolved.origin)
+			case settingSourceProfile:
+				hint = fmt.Sprintf("run: asyncstatus config set %s <value> --scope profile, or asyncstatus config unset %s --scope profile", s.key, s.key)
+			}
+			return &cliError{
+				class:   errorClassValidation,
+				message: fmt.Sprintf("invalid %s %q from %s: %v", s.key, resolved.value, describeSettingOrigin(resolved), err),
+				hint:    hint,
+			}
+		}
+	}
+	return nil
+}
+
+// describeSettingOrigin describes where a resolved value was read from
+func describeSettingOrigin(resolved resolvedSetting) string {
+	switch resolved.source {
+	case settingSourceFlag:
+		return resolved.origin
+	case settingSourceEnv:
+		return resolved.origin
+	case settingSourceProfile:
+		return fmt.Sprintf("profile %q", resolved.origin)
+	case settingSourceGlobal:
+		return resolved.origin
+	}
+	return "the defaults"
+}
+
+// getHTTPTimeout returns the timeout of requests to the API
+func getHTTPTimeout() time.Duration {
+	timeout, err := time.ParseDuration(getSetting("http-timeout"))
+	if err != nil {
+		return client.DefaultTimeout
+	}
+	return timeout
+}
+
+// applyColorSetting turns colored output on or off as configured. In auto
+// mode the color package decides from the terminal and NO_COLOR.
+func applyColorSetting() {
+	switch getSetting("color") {
+	case "always":
+		color.NoColor = false
+	case "never":
+		color.NoColor = true
+	}
+}
+
+// formatDate formats a date with the configured date format
+func formatDate(t time.Time) string {
+	layout := getSetting("date-format")
+	if preset, ok := datePresets[layout]; ok {
+		layout = preset
+	}
+	return t.Format(layout)
+}
+
+// formatTime formats a time of day with the configured time format, in the
+// user's zone
+func formatTime(t time.Time) string {
+	layout := getSetting("time-format")
+	if preset, ok := timePresets[layout]; ok {
+		layout = preset
+	}
+	return t.In(getTimezone().location).Format(layout)
+}
@@ -1,22 +1,23 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
 	"strings"
-	"time"
+	"text/template"
 
+	"asyncstatus.com/cli/client"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
 // showCmd represents the show command
 var showCmd = &cobra.Command{
-	Use:   "show [date]",
+	Use:   "show [date|range]",
 	Short: "Display a status update",
 	Long: `Display a status update for the specified date, showing all progress items, 
-blockers, completed tasks, and mood/notes.
+blockers, completed tasks, and mood/notes. A range of days shows each day
+that has an update.
 
 Examples:
   asyncstatus show                # Show today's status update
@@ -24,143 +25,279 @@ Examples:
   asyncstatus show today          # Show today's status update (explicit)
   asyncstatus show "2 days ago"   # Show status update from 2 days ago
   asyncstatus show "1 week ago"   # Show status update from 1 week ago
-  asyncstatus show 2024-01-15     # Show status update for specific date`,
-	Args: cobra.MaximumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+  asyncstatus show 2024-01-15     # Show status update for specific date
+  asyncstatus show friday         # Show status update from the last Friday
+  asyncstatus show "last monday"  # Show status update from Monday before today
+  asyncstatus show -- -3d         # Show status update from 3 days ago
+  asyncstatus show "last week"    # Show every update from last week
+  asyncstatus show 2024-W03       # Show every update from an ISO week
+  asyncstatus show monday..today  # Show every update from a range of days
+  asyncstatus show -o json        # Print as JSON for scripts
+  asyncstatus show --template standup
+  asyncstatus show --as slack --copy
+  asyncstatus show "last week" --as markdown
+  asyncstatus show --format '{{range .Items}}{{type .}}: {{.Content}}{{"\n"}}{{end}}'`,
+	Args: usageArgs(cobra.MaximumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
 		var date string
 		if len(args) == 1 {
 			date = args[0]
 		}
 		
-		if err := handleShowStatus(date); err != nil {
-			fmt.Printf("❌ Failed to retrieve status update: %v\n", err)
-			fmt.Println("   Make sure you're logged in: asyncstatus login")
-		}
+		return handleShowStatus(cmd.Context(), date)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(showCmd)
+	addCacheFlags(showCmd)
+	addTemplateFlags(showCmd)
+	showCmd.Flags().StringVar(&renderAsFlag, "as", "", "Render for pasting: slack, discord, teams, markdown or html")
+	showCmd.Flags().BoolVar(&copyFlag, "copy", false, "Copy the --as output to the clipboard instead of printing it")
 }
 
 // StatusUpdateResponse represents the API response for retrieving a status update
-type StatusUpdateResponse struct {
-	StatusUpdate *StatusUpdate `json:"statusUpdate"`
-	Message      string        `json:"message"`
-}
+type StatusUpdateResponse = client.StatusUpdateResponse
 
 // StatusUpdate represents a status update with items
-type StatusUpdate struct {
-	ID             string              `json:"id"`
-	MemberID       string              `json:"memberId"`
-	OrganizationID string              `json:"organizationId"`
-	TeamID         *string             `json:"teamId"`
-	EffectiveFrom  time.Time           `json:"effectiveFrom"`
-	EffectiveTo    time.Time           `json:"effectiveTo"`
-	Mood           *string             `json:"mood"`
-	Emoji          *string             `json:"emoji"`
-	Notes          *string             `json:"notes"`
-	IsDraft        bool                `json:"isDraft"`
-	Timezone       string              `json:"timezone"`
-	CreatedAt      time.Time           `json:"createdAt"`
-	UpdatedAt      time.Time           `json:"updatedAt"`
-	Items          []StatusUpdateItem  `json:"items"`
-	Member         Member              `json:"member"`
-	Team           *Team               `json:"team"`
-}
+type StatusUpdate = client.StatusUpdate
 
 // StatusUpdateItem represents a single item in a status update
-type StatusUpdateItem struct {
-	ID             string    `json:"id"`
-	StatusUpdateID string    `json:"statusUpdateId"`
-	Content        string    `json:"content"`
-	IsBlocker      bool      `json:"isBlocker"`
-	IsInProgress   bool      `json:"isInProgress"`
-	Order          int       `json:"order"`
-	CreatedAt      time.Time `json:"createdAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
-}
+type StatusUpdateItem = client.StatusUpdateItem
 
 // Member represents a member with user information
-type Member struct {
-	ID             string `json:"id"`
-	OrganizationID string `json:"organizationId"`
-	UserID         string `json:"userId"`
-	User           User   `json:"user"`
-}
+type Member = client.Member
 
 // User represents user information
-type User struct {
-	ID       string  `json:"id"`
-	Email    string  `json:"email"`
-	Name     string  `json:"name"`
-	Timezone *string `json:"timezone"`
-}
+type User = client.User
 
 // Team represents team information
-type Team struct {
-	ID             string `json:"id"`
-	OrganizationID string `json:"organizationId"`
-	Name           string `json:"name"`
-	Slug           string `json:"slug"`
-}
+type Team = client.Team
 
 // handleShowStatus processes retrieving a status update for the specified date
-func handleShowStatus(date string) error {
-	// Parse and normalize the date
-	normalizedDate, err := parseDate(date)
+func handleShowStatus(ctx context.Context, date string) error {
+	// Parse and normalize the date, which may also cover several days
+	dates := []string{todayInTimezone()}
+	if date != "" {
+		dateRange, err := parseDateRange(date, maxShowRangeDays)
+		if err != nil {
+			return err
+		}
+		dates = dateRange.dates()
+	}
+
+	policy, err := getCachePolicy()
 	if err != nil {
-		return fmt.Errorf("invalid date format: %v", err)
+		return err
 	}
 
+	chatFormat, err := getChatFormat()
+	if err != nil {
+		return err
+	}
+	if chatFormat != nil {
+		return handleShowAs(ctx, dates, policy, chatFormat)
+	}
+	tmpl, err := getStatusUpdateTemplate()
+	if err != nil {
+		return err
+	}
+	if tmpl != nil {
+		return handleShowTemplate(ctx, dates, policy, tmpl)
+	}
+	if machineOutput() {
+		return handleShowOutput(ctx, dates, policy)
+	}
+	if len(dates) > 1 {
+		return handleShowRange(ctx, dates, policy)
+	}
+	normalizedDate := dates[0]
+
 	// Get status update for the specified date
-	statusUpdate, err := getStatusUpdateByDate(normalizedDate)
+	statusUpdate, revalidation, err := getStatusUpdateWithCache(ctx, normalizedDate, policy)
 	if err != nil {
-		return fmt.Errorf("failed to fetch status update: %v", err)
+		return fmt.Errorf("failed to fetch status update: %w", err)
 	}
 
-	// Display the status update
-	if statusUpdate == nil {
-		dateDisplay := formatDateForDisplay(normalizedDate)
-		color.New(color.FgHiBlack).Printf("⧗ no updates found for %s\n", dateDisplay)
-		color.New(color.FgHiBlack).Println("  run:", color.New(color.FgWhite).Sprint("asyncstatus done \"your task\""), "to create one")
-		return nil
+	// Items queued offline for this date are shown alongside the server data
+	outbox, err := loadOutbox()
+	if err != nil {
+		return err
+	}
+	pendingItems := outbox.itemsForDate(normalizedDate, getActiveOrganization())
+
+	renderShowStatus(normalizedDate, statusUpdate, pendingItems)
+
+	// A cached copy was shown, wait for the background refresh and show it again if it changed
+	if revalidation != nil {
+		result := <-revalidation
+		if result.err != nil {
+			if isOfflineError(result.err) {
+				color.New(color.FgHiBlack).Println("  (offline, showing cached copy)")
+				return nil
+			}
+			return fmt.Errorf("failed to refresh status update: %w", result.err)
+		}
+		if !sameStatusUpdateVersion(statusUpdate, result.statusUpdate) {
+			fmt.Println()
+			color.New(color.FgHiBlack).Println("⧗ changed since cached copy, refreshed:")
+			renderShowStatus(normalizedDate, result.statusUpdate, pendingItems)
+		}
 	}
 
-	displayStatusUpdate(statusUpdate)
 	return nil
 }
 
-// getStatusUpdateByDate fetches a status update for a specific date using the API endpoint
-func getStatusUpdateByDate(targetDate string) (*StatusUpdate, error) {
-	endpoint := fmt.Sprintf("/cli/status-updates/by-date?date=%s", targetDate)
-	client, req, err := makeAuthenticatedRequest("GET", endpoint)
+// handleShowRange displays the status updates of several days, oldest first,
+// skipping days without updates
+func handleShowRange(ctx context.Context, dates []string, policy cachePolicy) error {
+	outbox, err := loadOutbox()
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	shown := 0
+	for _, date := range dates {
+		statusUpdate, err := getSettledStatusUpdate(ctx, date, policy)
+		if err != nil {
+			return err
+		}
+
+		pendingItems := outbox.itemsForDate(date, getActiveOrganization())
+		if statusUpdate == nil && len(pendingItems) == 0 {
+			continue
+		}
+		if shown > 0 {
+			color.New(color.FgHiBlack).Println("  ────────────────────────────────────")
+			fmt.Println()
+		}
+		renderShowStatus(date, statusUpdate, pendingItems)
+		shown++
 	}
 
-	resp, err := client.Do(req)
+	if shown == 0 {
+		color.New(color.FgHiBlack).Printf("⧗ no updates found from %s to %s\n", formatDateForDisplay(dates[0]), formatDateForDisplay(dates[len(dates)-1]))
+	}
+	return nil
+}
+
+// handleShowOutput prints the status updates of one or more days in a
+// machine-readable format, with the items still waiting in the outbox
+func handleShowOutput(ctx context.Context, dates []string, policy cachePolicy) error {
+	outbox, err := loadOutbox()
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %v", err)
+		return err
+	}
+
+	writer := newStatusUpdateWriter(getOutputFormat(), dates[0], dates[len(dates)-1])
+
+	for _, date := range dates {
+		statusUpdate, err := getSettledStatusUpdate(ctx, date, policy)
+		if err != nil {
+			return err
+		}
+		if statusUpdate != nil {
+			if err := writer.write(statusUpdate); err != nil {
+				return err
+			}
+		}
+		writer.addUnsynced(outbox.itemsForDate(date, getActiveOrganization()))
+	}
+
+	return writer.flush()
+}
+
+// handleShowTemplate prints the status update of each day with a template
+func handleShowTemplate(ctx context.Context, dates []string, policy cachePolicy, tmpl *template.Template) error {
+	for _, date := range dates {
+		statusUpdate, err := getSettledStatusUpdate(ctx, date, policy)
+		if err != nil {
+			return err
+		}
+		if statusUpdate == nil {
+			continue
+		}
+		if err := executeStatusUpdateTemplate(tmpl, statusUpdate); err != nil {
+			return err
+		}
 	}
-	defer resp.Body.Close()
+	return nil
+}
 
-	body, err := io.ReadAll(resp.Body)
+// getSettledStatusUpdate returns the status update of a day once any refresh
+// of a cached copy has finished, for output that is printed only once. When
+// the refresh fails because the API is unreachable the cached copy is kept.
+func getSettledStatusUpdate(ctx context.Context, date string, policy cachePolicy) (*StatusUpdate, error) {
+	statusUpdate, revalidation, err := getStatusUpdateWithCache(ctx, date, policy)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %v", err)
+		return nil, fmt.Errorf("failed to fetch status update for %s: %w", date, err)
+	}
+	if revalidation != nil {
+		result := <-revalidation
+		if result.err == nil {
+			return result.statusUpdate, nil
+		}
+		if !isOfflineError(result.err) {
+			return nil, fmt.Errorf("failed to refresh status update for %s: %w", date, result.err)
+		}
+	}
+	return statusUpdate, nil
+}
+
+// renderShowStatus displays a status update with its unsynced items, or a hint when there is nothing to show
+func renderShowStatus(date string, statusUpdate *StatusUpdate, pendingItems []OutboxItem) {
+	if statusUpdate == nil {
+		dateDisplay := formatDateForDisplay(date)
+		if len(pendingItems) > 0 {
+			color.New(color.FgWhite, color.Bold).Print("⧗ ")
+			color.New(color.FgWhite).Println(dateDisplay)
+			displayPendingOutboxItems(pendingItems)
+			return
+		}
+		color.New(color.FgHiBlack).Printf("⧗ no updates found for %s\n", dateDisplay)
+		color.New(color.FgHiBlack).Println("  run:", color.New(color.FgWhite).Sprint("asyncstatus done \"your task\""), "to create one")
+		return
+	}
+
+	displayStatusUpdate(statusUpdate)
+	displayPendingOutboxItems(pendingItems)
+}
+
+// displayPendingOutboxItems shows items that are queued locally and not yet on the server
+func displayPendingOutboxItems(items []OutboxItem) {
+	if len(items) == 0 {
+		return
 	}
 
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("server error (status %d): %s", resp.StatusCode, string(body))
+	fmt.Println()
+	color.New(color.FgHiBlack).Printf("  ⧗ unsynced (%d)\n", len(items))
+	for _, item := range items {
+		var itemColor *color.Color
+		switch item.Type {
+		case client.ItemTypeBlocker:
+			itemColor = color.New(color.FgRed)
+		case client.ItemTypeProgress:
+			itemColor = color.New(color.FgYellow)
+		default:
+			itemColor = color.New(color.FgGreen)
+		}
+		itemColor.Printf("    %-8s", item.Type)
+		color.New(color.FgWhite).Print(item.Message)
+		color.New(color.FgHiBlack).Println(" (unsynced)")
 	}
+	fmt.Println()
+	color.New(color.FgHiBlack).Println("  run:", color.New(color.FgWhite).Sprint("asyncstatus sync"), "to send queued items")
+}
 
-	var response StatusUpdateResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %v", err)
+// getStatusUpdateByDate fetches a status update for a specific date using the API endpoint
+func getStatusUpdateByDate(ctx context.Context, targetDate string) (*StatusUpdate, error) {
+	apiClient, err := newAPIClient(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	// Return the status update (can be nil if none exists for this date)
-	return response.StatusUpdate, nil
+	// The status update can be nil if none exists for this date
+	return apiClient.StatusUpdateByDate(ctx, targetDate)
 }
 
 // displayStatusUpdate formats and displays a status update
@@ -173,7 +310,7 @@ func displayStatusUpdate(statusUpdate *StatusUpdate) {
 	teamColor := color.New(color.FgMagenta)
 	
 	headerColor.Print("⧗ ")
-	dateColor.Println(statusUpdate.EffectiveFrom.Format("Monday, January 2, 2006"))
+	dateColor.Println(formatDate(statusUpdateDay(statusUpdate)))
 	
 	userColor.Print("  ")
 	userColor.Print(statusUpdate.Member.User.Name)
@@ -271,5 +408,5 @@ func displayStatusUpdate(statusUpdate *StatusUpdate) {
 
 	fmt.Println()
 	timeColor := color.New(color.FgHiBlack)
-	timeColor.Printf("  updated %s\n", statusUpdate.UpdatedAt.Format("15:04"))
+	timeColor.Printf("  updated %s\n", formatTime(statusUpdate.UpdatedAt))
 }
\ No newline at end of file
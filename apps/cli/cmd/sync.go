@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Send status updates queued while offline",
+	Long: `Send the status update items that were queued while the API was unreachable.
+
+When done, progress or blocker cannot reach the server, the item is stored in
+the outbox of the active profile with the date it was added for. sync replays the
+queue in order. Items whose day has already passed are added to that day's
+status update, and items that reached the server before are skipped.
+
+Examples:
+  asyncstatus sync`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleSync(cmd.Context())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+}
+
+// handleSync processes replaying the outbox
+func handleSync(ctx context.Context) error {
+	outbox, err := loadOutbox()
+	if err != nil {
+		return err
+	}
+
+	if len(outbox.Items) == 0 {
+		color.New(color.FgHiBlack).Println("⧗ nothing to sync")
+		return nil
+	}
+
+	apiClient, err := newAPIClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	color.New(color.FgHiBlack).Printf("⧗ syncing %d queued item(s)...\n", len(outbox.Items))
+
+	_, err = syncOutbox(ctx, apiClient, outbox, func(result syncResult) {
+		if result.Duplicate {
+			color.New(color.FgHiBlack).Printf("  = %s %s (already saved)\n", result.Item.Type, result.Item.Message)
+			return
+		}
+		color.New(color.FgGreen).Print("  ✓ ")
+		color.New(color.FgWhite).Printf("%s %s", result.Item.Type, result.Item.Message)
+		color.New(color.FgHiBlack).Printf(" (%s)\n", formatDateForDisplay(result.Item.Date))
+	})
+	if err != nil {
+		if len(outbox.Items) > 0 {
+			color.New(color.FgHiBlack).Printf("  %d item(s) still queued\n", len(outbox.Items))
+		}
+		return err
+	}
+
+	color.New(color.FgGreen).Println("⧗ all queued items synced")
+	return nil
+}
@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	formatFlag   string
+	templateFlag string
+)
+
+// builtinTemplates can be selected with --template without creating a file.
+// A file with the same name in the templates directory takes precedence.
+var builtinTemplates = map[string]string{
+	"oneline": `{{date . "Mon Jan 2"}}: {{len .Items}} items{{with items "blocker" .}}, {{len .}} blocked{{end}}`,
+	"standup": `{{date .}}
+{{with items "done" .}}Done:
+{{range .}}- {{.Content}}
+{{end}}{{end}}{{with items "progress" .}}In progress:
+{{range .}}- {{.Content}}
+{{end}}{{end}}{{with items "blocker" .}}Blocked:
+{{range .}}- {{.Content}}
+{{end}}{{end}}{{with .Mood}}Mood: {{.}}
+{{end}}{{with .Notes}}Notes: {{.}}
+{{end}}`,
+}
+
+// templateColors are the color names the color template function accepts
+var templateColors = map[string]color.Attribute{
+	"black":   color.FgBlack,
+	"red":     color.FgRed,
+	"green":   color.FgGreen,
+	"yellow":  color.FgYellow,
+	"blue":    color.FgBlue,
+	"magenta": color.FgMagenta,
+	"cyan":    color.FgCyan,
+	"white":   color.FgWhite,
+	"gray":    color.FgHiBlack,
+	"bold":    color.Bold,
+}
+
+// addTemplateFlags registers the --format and --template flags on a command
+func addTemplateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&formatFlag, "format", "", "Print each status update with a Go template, e.g. '{{range .Items}}{{.Content}}{{\"\\n\"}}{{end}}'")
+	cmd.Flags().StringVar(&templateFlag, "template", "", "Print each status update with a named template: a file in the templates directory, oneline or standup")
+}
+
+// getTemplatesDir returns the directory holding named templates
+func getTemplatesDir() string {
+	return filepath.Join(getConfigDir(), "templates")
+}
+
+// templateFuncs are the helpers available to --format and --template
+var templateFuncs = template.FuncMap{
+	"type": func(item StatusUpdateItem) string {
+		return string(item.Type())
+	},
+	"items": func(itemType string, statusUpdate *StatusUpdate) []StatusUpdateItem {
+		var items []StatusUpdateItem
+		for _, item := range statusUpdate.Items {
+			if string(item.Type()) == itemType {
+				items = append(items, item)
+			}
+		}
+		return items
+	},
+	"contents": func(items []StatusUpdateItem) []string {
+		contents := make([]string, 0, len(items))
+		for _, item := range items {
+			contents = append(contents, item.Content)
+		}
+		return contents
+	},
+	"date":     templateDate,
+	"time":     templateTime,
+	"color":    templateColor,
+	"truncate": templateTruncate,
+	"join": func(sep string, values []string) string {
+		return strings.Join(values, sep)
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// templateDate formats the day of a status update, or a time, with the
+// date-format setting or the given Go layout
+func templateDate(value any, layout ...string) (string, error) {
+	var t time.Time
+	switch v := value.(type) {
+	case *StatusUpdate:
+		t = statusUpdateDay(v)
+	case time.Time:
+		t = v.In(getTimezone().location)
+	default:
+		return "", fmt.Errorf("date expects a status update or a time, got %T", value)
+	}
+	if len(layout) > 0 {
+		return t.Format(layout[0]), nil
+	}
+	return formatDate(t), nil
+}
+
+// templateTime formats a time in the user's zone with the time-format setting
+// or the given Go layout
+func templateTime(t time.Time, layout ...string) string {
+	if len(layout) > 0 {
+		return t.In(getTimezone().location).Format(layout[0])
+	}
+	return formatTime(t)
+}
+
+// templateColor colors text, following the color setting
+func templateColor(name string, text any) (string, error) {
+	attribute, ok := templateColors[name]
+	if !ok {
+		names := make([]string, 0, len(templateColors))
+		for name := range templateColors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("unknown color %q, expected one of: %s", name, strings.Join(names, ", "))
+	}
+	return color.New(attribute).Sprint(text), nil
+}
+
+// templateTruncate shortens text to at most n characters, ending in "…"
+func templateTruncate(n int, text string) string {
+	runes := []rune(text)
+	if n < 1 || len(runes) <= n {
+		return text
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// getStatusUpdateTemplate returns the template selected with --format or
+// --template, or nil if neither is set
+func getStatusUpdateTemplate() (*template.Template, error) {
+	if formatFlag == "" && templateFlag == "" {
+		return nil, nil
+	}
+	if formatFlag != "" && templateFlag != "" {
+		return nil, validationError("--format and --template cannot be used together")
+	}
+
+	name, text := "format", formatFlag
+	if machineOutput() {
+		if templateFlag != "" {
+			name = "template"
+		}
+		return nil, validationError("--%s prints text and cannot be combined with --output %s", name, getOutputFormat())
+	}
+	if templateFlag != "" {
+		var err error
+		name = templateFlag
+		if text, err = loadNamedTemplate(templateFlag); err != nil {
+			return nil, err
+		}
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, validationError("invalid template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// loadNamedTemplate reads a template from the templates directory, falling
+// back to the built-in templates
+func loadNamedTemplate(name string) (string, error) {
+	if !profileNameRegex.MatchString(name) {
+		return "", validationError("invalid template name %q, use letters, digits, '.', '_' and '-'", name)
+	}
+
+	content, err := os.ReadFile(filepath.Join(getTemplatesDir(), name+".tmpl"))
+	if err == nil {
+		return string(content), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read template %s: %v", name, err)
+	}
+	if builtin, ok := builtinTemplates[name]; ok {
+		return builtin, nil
+	}
+
+	names := listTemplateNames()
+	return "", &cliError{
+		class:   errorClassValidation,
+		message: fmt.Sprintf("template %q not found, available: %s", name, strings.Join(names, ", ")),
+		hint:    fmt.Sprintf("save it as %s", filepath.Join(getTemplatesDir(), name+".tmpl")),
+	}
+}
+
+// listTemplateNames returns the names of the saved and built-in templates
+func listTemplateNames() []string {
+	seen := map[string]bool{}
+	for name := range builtinTemplates {
+		seen[name] = true
+	}
+	if entries, err := os.ReadDir(getTemplatesDir()); err == nil {
+		for _, entry := range entries {
+			if name, ok := strings.CutSuffix(entry.Name(), ".tmpl"); ok && !entry.IsDir() {
+				seen[name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// executeStatusUpdateTemplate prints a status update with a template, adding
+// a final newline if the template doesn't end with one
+func executeStatusUpdateTemplate(tmpl *template.Template, statusUpdate *StatusUpdate) error {
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, statusUpdate); err != nil {
+		return validationError("failed to run template: %v", err)
+	}
+	if out.Len() > 0 && !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	if _, err := os.Stdout.Write(out.Bytes()); err != nil {
+		return fmt.Errorf("failed to write output: %v", err)
+	}
+	return nil
+}
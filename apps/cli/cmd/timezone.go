@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// userTimezone is the zone days and times are counted in, with its IANA name
+// for the API
+type userTimezone struct {
+	name     string
+	location *time.Location
+}
+
+// activeTimezone caches the zone of this run once resolved
+var activeTimezone *userTimezone
+
+// validateTimezoneSetting accepts account, local or an IANA zone name
+func validateTimezoneSetting(value string) error {
+	if value == "account" || value == "local" {
+		return nil
+	}
+	if _, err := loadTimezone(value); err != nil {
+		return fmt.Errorf("expected account, local or an IANA zone such as Europe/Berlin")
+	}
+	return nil
+}
+
+// loadTimezone loads an IANA zone by name. Unlike time.LoadLocation it
+// rejects "" and "Local", which don't name a zone the API knows.
+func loadTimezone(name string) (*userTimezone, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	return &userTimezone{name: name, location: location}, nil
+}
+
+// getTimezone returns the zone that "today", "yesterday" and displayed times
+// are computed in: the timezone setting, which by default is the zone of the
+// AsyncStatus account. Without a readable account zone the machine's is used.
+func getTimezone() *userTimezone {
+	if activeTimezone != nil {
+		return activeTimezone
+	}
+
+	switch setting := getSetting("timezone"); setting {
+	case "local":
+		activeTimezone = localTimezone()
+	case "account":
+		if zone, err := accountTimezone(); err == nil {
+			activeTimezone = zone
+			warnTimezoneMismatch(zone)
+		} else {
+			activeTimezone = localTimezone()
+		}
+	default:
+		zone, err := loadTimezone(setting)
+		if err != nil {
+			zone = localTimezone()
+		}
+		activeTimezone = zone
+	}
+	return activeTimezone
+}
+
+// accountTimezone returns the zone of the AsyncStatus account, as recorded in
+// the stored token. Tokens are reissued daily, so a changed zone shows up soon.
+func accountTimezone() (*userTimezone, error) {
+	token, err := getCurrentToken()
+	if err != nil {
+		return nil, err
+	}
+	claims, err := parseTokenClaims(token)
+	if err != nil {
+		return nil, err
+	}
+	return loadTimezone(claims.User.Timezone)
+}
+
+// localTimezone returns the zone of this machine. The API needs an IANA name,
+// which is taken from TZ or the /etc/localtime link, falling back to a fixed
+// offset zone.
+func localTimezone() *userTimezone {
+	if name := strings.TrimPrefix(os.Getenv("TZ"), ":"); name != "" {
+		if zone, err := loadTimezone(name); err == nil {
+			return zone
+		}
+	}
+	if target, err := filepath.EvalSymlinks("/etc/localtime"); err == nil {
+		if _, name, ok := strings.Cut(target, "zoneinfo/"); ok {
+			if zone, err := loadTimezone(name); err == nil {
+				return zone
+			}
+		}
+	}
+
+	// Etc/GMT zones have inverted signs and only exist for whole hours
+	_, offset := time.Now().Zone()
+	if offset%3600 == 0 {
+		name := "UTC"
+		if hours := offset / 3600; hours > 0 {
+			name = fmt.Sprintf("Etc/GMT-%d", hours)
+		} else if hours < 0 {
+			name = fmt.Sprintf("Etc/GMT+%d", -hours)
+		}
+		if zone, err := loadTimezone(name); err == nil {
+			return zone
+		}
+	}
+	return &userTimezone{name: "UTC", location: time.UTC}
+}
+
+// warnTimezoneMismatch warns when this machine is currently on another offset
+// than the account, since "today" may then not be the day the clock shows.
+// Choosing a zone explicitly silences it.
+func warnTimezoneMismatch(account *userTimezone) {
+	if getSettingSource("timezone") != settingSourceDefault {
+		return
+	}
+
+	now := time.Now()
+	localName, localOffset := now.Zone()
+	_, accountOffset := now.In(account.location).Zone()
+	if localOffset == accountOffset {
+		return
+	}
+
+	color.New(color.FgYellow).Fprintf(os.Stderr, "⧗ this computer is on %s (%s) but your account uses %s (%s), dates follow the account\n",
+		localName, formatUTCOffset(localOffset), account.name, formatUTCOffset(accountOffset))
+	color.New(color.FgHiBlack).Fprintln(os.Stderr, "  pass --tz local to use this computer's zone, or run: asyncstatus config set timezone account")
+}
+
+// formatUTCOffset formats an offset in seconds as UTC+02:00
+func formatUTCOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("UTC%s%02d:%02d", sign, offset/3600, offset%3600/60)
+}
+
+// nowInTimezone returns the current time in the user's zone
+func nowInTimezone() time.Time {
+	return time.Now().In(getTimezone().location)
+}
+
+// todayInTimezone returns today's date in the user's zone as YYYY-MM-DD
+func todayInTimezone() string {
+	return nowInTimezone().Format("2006-01-02")
+}
+
+// statusUpdateDate returns the YYYY-MM-DD day a status update belongs to.
+// Its day starts at midnight in the zone it was created in; status updates
+// created before the CLI sent a zone start at midnight UTC instead.
+func statusUpdateDate(statusUpdate *StatusUpdate) string {
+	if location, err := time.LoadLocation(statusUpdate.Timezone); err == nil {
+		start := statusUpdate.EffectiveFrom.In(location)
+		if start.Hour() == 0 && start.Minute() == 0 {
+			return start.Format("2006-01-02")
+		}
+	}
+	return statusUpdate.EffectiveFrom.UTC().Format("2006-01-02")
+}
+
+// statusUpdateDay returns the day of a status update as a time for display
+func statusUpdateDay(statusUpdate *StatusUpdate) time.Time {
+	day, err := time.Parse("2006-01-02", statusUpdateDate(statusUpdate))
+	if err != nil {
+		return statusUpdate.EffectiveFrom
+	}
+	return day
+}
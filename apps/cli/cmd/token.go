@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"asyncstatus.com/cli/client"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// maxTokenDays is the longest lifetime the API grants automation tokens
+const maxTokenDays = 365
+
+var (
+	tokenName string
+	tokenDays int
+)
+
+// tokenCmd represents the token command
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Print or create tokens for scripts and CI",
+	Long: `Print the token of the active profile, or create a long-lived token for
+automation. Set a token in ASYNCSTATUS_TOKEN to use it without a config file,
+for example in CI, containers or cron jobs.
+
+Examples:
+  asyncstatus token print
+  asyncstatus token create --name github-actions --days 90
+  ASYNCSTATUS_TOKEN=$(asyncstatus token create --name cron) asyncstatus show
+  asyncstatus token list
+  asyncstatus token revoke <id>`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
+
+// tokenPrintCmd represents the token print command
+var tokenPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Print the token of the active profile",
+	Long: `Print the token of the active profile to stdout, refreshing it first if
+needed. The token is valid for 30 days; use "token create" for longer.`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleTokenPrint(cmd.Context())
+	},
+}
+
+// tokenCreateCmd represents the token create command
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a long-lived token for automation",
+	Long: `Create a token for automation and print it to stdout. The token is not
+stored; keep it in your CI secrets and pass it in ASYNCSTATUS_TOKEN.
+
+Creating tokens needs the web session saved by "asyncstatus login", so tokens
+can't be used to create further tokens. A token stays valid until it expires
+or is revoked with "asyncstatus token revoke", even after logging out.`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleTokenCreate(cmd.Context(), tokenName, tokenDays)
+	},
+}
+
+// tokenListCmd represents the token list command
+var tokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the long-lived tokens you created",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleTokenList(cmd.Context())
+	},
+}
+
+// tokenRevokeCmd represents the token revoke command
+var tokenRevokeCmd = &cobra.Command{
+	Use:   "revoke <id>",
+	Short: "Revoke a long-lived token",
+	Long: `Revoke a token created with "asyncstatus token create", so it stops working
+right away. "asyncstatus token list" shows the IDs.`,
+	Args: usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleTokenRevoke(cmd.Context(), args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tokenCmd)
+	tokenCmd.AddCommand(tokenPrintCmd)
+	tokenCmd.AddCommand(tokenCreateCmd)
+	tokenCmd.AddCommand(tokenListCmd)
+	tokenCmd.AddCommand(tokenRevokeCmd)
+	tokenCreateCmd.Flags().StringVar(&tokenName, "name", "", "Name describing where the token is used")
+	tokenCreateCmd.Flags().IntVar(&tokenDays, "days", 90, fmt.Sprintf("Days until the token expires (1-%d)", maxTokenDays))
+	tokenCreateCmd.MarkFlagRequired("name")
+}
+
+// handleTokenPrint prints the current token
+func handleTokenPrint(ctx context.Context) error {
+	token, err := getFreshToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(token)
+	return nil
+}
+
+// handleTokenCreate creates a long-lived token and prints it. Progress goes
+// to stderr so the token can be captured from stdout.
+func handleTokenCreate(ctx context.Context, name string, days int) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return validationError("token name is required")
+	}
+	if days < 1 || days > maxTokenDays {
+		return validationError("--days must be between 1 and %d", maxTokenDays)
+	}
+
+	apiClient, err := newSessionAPIClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	color.New(color.FgHiBlack).Fprintf(os.Stderr, "⧗ creating token %s...\n", name)
+	created, err := apiClient.CreateToken(ctx, &client.CreateTokenRequest{Name: name, ExpiresInDays: days})
+	if err != nil {
+		return fmt.Errorf("failed to create token: %w", err)
+	}
+
+	// Check it the same way a stored token would be
+	if _, err := verifyToken(ctx, getAPIURL(), created.Token); err != nil {
+		return fmt.Errorf("refusing to print token: %w", err)
+	}
+
+	color.New(color.FgGreen).Fprintf(os.Stderr, "  ✓ valid until %s, revoke it with: asyncstatus token revoke %s\n", created.ExpiresAt.Local().Format("January 2, 2006"), created.ID)
+	fmt.Println(created.Token)
+	return nil
+}
+
+// handleTokenList prints the long-lived tokens that still work
+func handleTokenList(ctx context.Context) error {
+	apiClient, err := newSessionAPIClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := apiClient.ListTokens(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list tokens: %w", err)
+	}
+
+	if len(tokens) == 0 {
+		color.New(color.FgHiBlack).Println("⧗ no tokens, create one with: asyncstatus token create --name <name>")
+		return nil
+	}
+
+	idWidth, nameWidth := 0, 0
+	for _, token := range tokens {
+		idWidth = max(idWidth, len(token.ID))
+		nameWidth = max(nameWidth, len(token.Name))
+	}
+	for _, token := range tokens {
+		color.New(color.FgWhite).Printf("%-*s", idWidth, token.ID)
+		color.New(color.FgCyan).Printf("  %-*s", nameWidth, token.Name)
+		color.New(color.FgHiBlack).Printf("  created %s, expires %s\n", token.CreatedAt.Local().Format("January 2, 2006"), token.ExpiresAt.Local().Format("January 2, 2006"))
+	}
+	return nil
+}
+
+// handleTokenRevoke revokes a long-lived token by its ID
+func handleTokenRevoke(ctx context.Context, id string) error {
+	apiClient, err := newSessionAPIClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := apiClient.RevokeToken(ctx, id); err != nil {
+		var apiErr *client.APIError
+		if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+			return validationError("no token with ID %s, see asyncstatus token list", id)
+		}
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	color.New(color.FgGreen).Print("⧗ revoked token ")
+	color.New(color.FgCyan).Println(id)
+	return nil
+}
+
+// readStdinSecret reads a password or token piped on stdin, dropping the
+// trailing newline
+func readStdinSecret(what string) (string, error) {
+	content, err := io.ReadAll(io.LimitReader(os.Stdin, 64*1024))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from stdin: %w", what, err)
+	}
+
+	secret := strings.TrimSuffix(strings.TrimSuffix(string(content), "\n"), "\r")
+	if strings.TrimSpace(secret) == "" {
+		return "", validationError("no %s on stdin", what)
+	}
+	return secret, nil
+}
+
+// handleTokenLogin stores a token piped on stdin after verifying it
+func handleTokenLogin(ctx context.Context) error {
+	if authBaseURL == "" {
+		authBaseURL = getAPIURL()
+	}
+
+	token, err := readStdinSecret("token")
+	if err != nil {
+		return err
+	}
+	token = strings.TrimSpace(token)
+
+	claims, err := verifyToken(ctx, authBaseURL, token)
+	if err != nil {
+		return fmt.Errorf("login failed: refusing to store token: %w", err)
+	}
+	color.New(color.FgHiBlack).Println("  ✓ token signature verified")
+
+	if err := storeCredentials(claims.User.Email, token, ""); err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+
+	color.New(color.FgGreen).Print("⧗ logged in as ")
+	color.New(color.FgCyan).Print(claims.User.Email)
+	// Without a session the token can't be refreshed, so say how long it lasts
+	if claims.ExpiresAt != nil {
+		color.New(color.FgHiBlack).Printf(" until %s", claims.ExpiresAt.Local().Format("January 2, 2006"))
+	}
+	fmt.Println()
+	return nil
+}
+
+// stdinIsTerminal reports whether the CLI may prompt on stdin
+func stdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
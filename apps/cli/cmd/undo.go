@@ -1,10 +1,9 @@
 package cmd
 
 import (
-	"encoding/json"
-	"fmt"
-	"io"
+	"context"
 
+	"asyncstatus.com/cli/client"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -17,12 +16,9 @@ var undoCmd = &cobra.Command{
 
 Examples:
   asyncstatus undo`,
-	Args: cobra.NoArgs,
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := handleUndoStatus(); err != nil {
-			color.New(color.FgRed).Printf("⧗ failed: %v\n", err)
-			color.New(color.FgHiBlack).Println("  run:", color.New(color.FgWhite).Sprint("asyncstatus login"), "first")
-		}
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleUndoStatus(cmd.Context())
 	},
 }
 
@@ -31,44 +27,26 @@ func init() {
 }
 
 // UndoResponse represents the API response for removing a status update item
-type UndoResponse struct {
-	Success             bool   `json:"success"`
-	DeletedStatusUpdate bool   `json:"deletedStatusUpdate"`
-	Message             string `json:"message"`
-}
+type UndoResponse = client.UndoResponse
 
 // handleUndoStatus processes removing the last status update item
-func handleUndoStatus() error {
+func handleUndoStatus(ctx context.Context) error {
 	color.New(color.FgHiBlack).Println("⧗ undoing last item...")
 	
-	endpoint := "/cli/status-updates/last"
-	client, req, err := makeAuthenticatedRequest("DELETE", endpoint)
+	apiClient, err := newAPIClient(ctx)
 	if err != nil {
 		return err
 	}
 	
-	// Send request
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	response, err := apiClient.UndoLastStatusUpdateItem(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %v", err)
+		return err
 	}
 	
-	// Check response status
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("server error (status %d): %s", resp.StatusCode, string(body))
-	}
-
-	// Parse response
-	var response UndoResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return fmt.Errorf("failed to parse response: %v", err)
+	// The response doesn't include the updated status update, drop today's cached copy
+	invalidateStatusUpdateCache(todayInTimezone())
+	if today, err := parseDate(""); err == nil {
+		invalidateStatusUpdateCache(today)
 	}
 	
 	// Display result message
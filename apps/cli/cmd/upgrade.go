@@ -94,6 +94,10 @@ func handleUpgrade() error {
 	
 	// Prompt for upgrade
 	if !forceUpgrade {
+		if !stdinIsTerminal() {
+			return validationError("stdin is not a terminal, use --force to upgrade without confirmation")
+		}
+		
 		color.New(color.FgYellow).Printf("⧗ upgrade to %s? [y/N]: ", latestVersion)
 		
 		var response string
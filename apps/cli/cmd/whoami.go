@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"asyncstatus.com/cli/client"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var whoamiJSON bool
+
+// whoamiCmd represents the whoami command
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show the logged in user",
+	Long: `Show who you are logged in as: user, timezone, organization, role and
+teams, the server and when the token expires. The stored token is verified
+against the signing keys published by the API, so a token that the server did
+not issue is reported instead of trusted.
+
+If the API can't be reached, what the token records is shown instead, without
+role and teams.
+
+Examples:
+  asyncstatus whoami
+  asyncstatus whoami --json
+  asyncstatus --profile staging whoami`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return handleWhoami(cmd.Context(), whoamiJSON)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whoamiCmd)
+	whoamiCmd.Flags().BoolVar(&whoamiJSON, "json", false, "Print as JSON")
+}
+
+// whoamiTeam is a team in the output of whoami
+type whoamiTeam struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// whoamiOrganization is an organization in the output of whoami
+type whoamiOrganization struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	Slug string `json:"slug"`
+}
+
+// whoamiResult is the output of whoami
+type whoamiResult struct {
+	User struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Timezone string `json:"timezone,omitempty"`
+	} `json:"user"`
+	Organization   *whoamiOrganization `json:"organization"`
+	Role           string              `json:"role,omitempty"`
+	Teams          []whoamiTeam        `json:"teams"`
+	Profile        string              `json:"profile,omitempty"`
+	TokenSource    string              `json:"tokenSource"`
+	APIURL         string              `json:"apiUrl"`
+	TokenExpiresAt *time.Time          `json:"tokenExpiresAt,omitempty"`
+	Verified       bool                `json:"verified"`
+	// Online is false when the API couldn't be reached and only the token was read
+	Online bool `json:"online"`
+}
+
+// handleWhoami prints the user of the stored token after verifying it,
+// with their membership details from the API
+func handleWhoami(ctx context.Context, asJSON bool) error {
+	token, err := getCurrentToken()
+	if err != nil {
+		return err
+	}
+
+	apiURL := getAPIURL()
+	claims, err := verifyToken(ctx, apiURL, token)
+	if err != nil {
+		return err
+	}
+
+	result := whoamiResult{APIURL: apiURL, Verified: true, Teams: []whoamiTeam{}}
+	result.User.ID = claims.User.ID
+	result.User.Name = claims.User.Name
+	result.User.Email = claims.User.Email
+	result.User.Timezone = claims.User.Timezone
+	if claims.ExpiresAt != nil {
+		expiresAt := claims.ExpiresAt.Time
+		result.TokenExpiresAt = &expiresAt
+	}
+
+	// ASYNCSTATUS_TOKEN works without any profile
+	result.TokenSource = "profile"
+	if tokenFromEnv() != "" {
+		result.TokenSource = "env"
+	} else {
+		_, profileName, err := loadActiveProfile()
+		if err != nil {
+			return err
+		}
+		result.Profile = profileName
+	}
+
+	if slug := getActiveOrganization(); slug != "" {
+		result.Organization = &whoamiOrganization{Slug: slug}
+	} else if claims.User.ActiveOrganizationSlug != "" {
+		result.Organization = &whoamiOrganization{Slug: claims.User.ActiveOrganizationSlug}
+	}
+
+	whoami, err := fetchWhoami(ctx)
+	switch {
+	case err == nil:
+		result.Online = true
+		applyWhoami(&result, whoami)
+	case classifyError(err).class == errorClassNetwork:
+		color.New(color.FgYellow).Fprintln(os.Stderr, "⧗ could not reach the API, showing what the token records")
+	default:
+		return err
+	}
+
+	// Building the client may have refreshed the token, which refreshStoredToken
+	// verified already
+	if current, err := getCurrentToken(); err == nil && current != token {
+		if refreshed, err := parseTokenClaims(current); err == nil && refreshed.ExpiresAt != nil {
+			expiresAt := refreshed.ExpiresAt.Time
+			result.TokenExpiresAt = &expiresAt
+		}
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	printWhoami(&result)
+	return nil
+}
+
+// fetchWhoami asks the API for the current user and membership
+func fetchWhoami(ctx context.Context) (*client.Whoami, error) {
+	apiClient, err := newAPIClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return apiClient.Whoami(ctx)
+}
+
+// applyWhoami replaces what the token records with the current details from the API
+func applyWhoami(result *whoamiResult, whoami *client.Whoami) {
+	result.User.Name = whoami.User.Name
+	result.User.Email = whoami.User.Email
+	if whoami.User.Timezone != nil {
+		result.User.Timezone = *whoami.User.Timezone
+	}
+
+	result.Organization = &whoamiOrganization{
+		ID:   whoami.Organization.ID,
+		Name: whoami.Organization.Name,
+		Slug: whoami.Organization.Slug,
+	}
+	result.Role = whoami.Member.Role
+
+	for _, team := range whoami.Teams {
+		result.Teams = append(result.Teams, whoamiTeam{ID: team.ID, Name: team.Name, Slug: team.Slug})
+	}
+}
+
+// printWhoami prints the result of whoami for humans
+func printWhoami(result *whoamiResult) {
+	color.New(color.FgGreen).Print("⧗ ")
+	color.New(color.FgWhite, color.Bold).Print(result.User.Name)
+	color.New(color.FgCyan).Printf(" (%s)\n", result.User.Email)
+
+	printWhoamiField("timezone", result.User.Timezone)
+
+	if result.Organization != nil {
+		organization := result.Organization.Slug
+		if result.Organization.Name != "" {
+			organization = result.Organization.Name + " (" + result.Organization.Slug + ")"
+		}
+		printWhoamiField("organization", organization)
+	}
+
+	if result.Online {
+		printWhoamiField("role", result.Role)
+
+		teams := make([]string, 0, len(result.Teams))
+		for _, team := range result.Teams {
+			teams = append(teams, team.Name)
+		}
+		if len(teams) == 0 {
+			teams = append(teams, "none")
+		}
+		printWhoamiField("teams", strings.Join(teams, ", "))
+	}
+
+	if result.Profile != "" {
+		printWhoamiField("profile", result.Profile)
+	} else {
+		printWhoamiField("profile", "none, using ASYNCSTATUS_TOKEN")
+	}
+	printWhoamiField("api", result.APIURL)
+
+	if result.TokenExpiresAt != nil {
+		color.New(color.FgHiBlack).Printf("  %-14s", "expires:")
+		color.New(color.FgWhite).Print(result.TokenExpiresAt.Local().Format("January 2, 2006 at 15:04"))
+		color.New(color.FgHiBlack).Printf(" (in %s)\n", formatRemaining(time.Until(*result.TokenExpiresAt)))
+	}
+
+	color.New(color.FgGreen).Println("  ✓ token signature verified")
+}
+
+// printWhoamiField prints one aligned line of whoami, skipping empty values
+func printWhoamiField(label, value string) {
+	if value == "" {
+		return
+	}
+	color.New(color.FgHiBlack).Printf("  %-14s", label+":")
+	color.New(color.FgWhite).Println(value)
+}
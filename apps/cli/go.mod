@@ -10,7 +10,9 @@ require (
 	github.com/golang-jwt/jwt/v5 v5.2.1
 	github.com/savioxavier/termlink v1.4.3
 	github.com/spf13/cobra v1.9.1
+	golang.org/x/crypto v0.40.0
 	golang.org/x/term v0.33.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,10 @@
+CREATE TABLE `two_factor` (
+	`id` text PRIMARY KEY NOT NULL,
+	`secret` text NOT NULL,
+	`backup_codes` text NOT NULL,
+	`user_id` text NOT NULL,
+	FOREIGN KEY (`user_id`) REFERENCES `user`(`id`) ON UPDATE no action ON DELETE cascade
+);
+--> statement-breakpoint
+CREATE INDEX `user_two_factor_id_index` ON `two_factor` (`user_id`);--> statement-breakpoint
+ALTER TABLE `user` ADD `two_factor_enabled` integer DEFAULT false NOT NULL;
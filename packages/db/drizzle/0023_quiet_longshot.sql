@@ -0,0 +1,2 @@
+ALTER TABLE `status_update_item` ADD `idempotency_key` text;--> statement-breakpoint
+CREATE UNIQUE INDEX `status_update_item_idempotency_key_unique` ON `status_update_item` (`status_update_id`,`idempotency_key`);
\ No newline at end of file
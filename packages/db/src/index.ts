@@ -211,6 +211,7 @@ export {
   TeamMembershipUpdate,
   teamMembership,
 } from "./team-membership";
+export { TwoFactor, TwoFactorInsert, TwoFactorUpdate, twoFactor } from "./two-factor";
 export { User, UserInsert, UserUpdate, user } from "./user";
 export {
   UserTimezoneHistory,
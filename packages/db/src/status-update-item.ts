@@ -1,4 +1,4 @@
-import { index, integer, sqliteTable, text } from "drizzle-orm/sqlite-core";
+import { index, integer, sqliteTable, text, uniqueIndex } from "drizzle-orm/sqlite-core";
 import { z } from "zod/v4";
 import { createInsertSchema, createSelectSchema, createUpdateSchema } from "./common";
 import { statusUpdate } from "./status-update";
@@ -14,12 +14,18 @@ export const statusUpdateItem = sqliteTable(
     isBlocker: integer("is_blocker", { mode: "boolean" }).notNull().default(false),
     isInProgress: integer("is_in_progress", { mode: "boolean" }).notNull().default(false),
     order: integer("order").notNull(),
+    // Set by clients that retry inserts, so an item is only added once
+    idempotencyKey: text("idempotency_key"),
     createdAt: integer("created_at", { mode: "timestamp_ms" }).notNull(),
     updatedAt: integer("updated_at", { mode: "timestamp_ms" }).notNull(),
   },
   (t) => [
     index("status_update_item_update_id_index").on(t.statusUpdateId),
     index("status_update_item_blocker_index").on(t.isBlocker),
+    uniqueIndex("status_update_item_idempotency_key_unique").on(
+      t.statusUpdateId,
+      t.idempotencyKey,
+    ),
   ],
 );
 
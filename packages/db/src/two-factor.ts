@@ -0,0 +1,35 @@
+import { index, sqliteTable, text } from "drizzle-orm/sqlite-core";
+import { z } from "zod/v4";
+import { createInsertSchema, createSelectSchema, createUpdateSchema } from "./common";
+import { user } from "./user";
+
+export const twoFactor = sqliteTable(
+  "two_factor",
+  {
+    id: text("id").primaryKey(),
+    secret: text("secret").notNull(),
+    backupCodes: text("backup_codes").notNull(),
+    userId: text("user_id")
+      .notNull()
+      .references(() => user.id, { onDelete: "cascade" }),
+  },
+  (t) => [index("user_two_factor_id_index").on(t.userId)],
+);
+
+export const TwoFactor = createSelectSchema(twoFactor, {
+  secret: z.string().min(1),
+  backupCodes: z.string().min(1),
+});
+export type TwoFactor = z.output<typeof TwoFactor>;
+
+export const TwoFactorInsert = createInsertSchema(twoFactor, {
+  secret: z.string().min(1),
+  backupCodes: z.string().min(1),
+});
+export type TwoFactorInsert = z.output<typeof TwoFactorInsert>;
+
+export const TwoFactorUpdate = createUpdateSchema(twoFactor, {
+  secret: z.string().min(1),
+  backupCodes: z.string().min(1),
+});
+export type TwoFactorUpdate = z.output<typeof TwoFactorUpdate>;
@@ -18,6 +18,7 @@ export const user = sqliteTable(
     showOnboarding: integer("show_onboarding", { mode: "boolean" }).notNull().default(false),
     onboardingStep: text("onboarding_step", { enum: ["first-step", "second-step", "third-step"] }),
     onboardingCompletedAt: integer("onboarding_completed_at", { mode: "timestamp" }),
+    twoFactorEnabled: integer("two_factor_enabled", { mode: "boolean" }).notNull().default(false),
     createdAt: integer("created_at", { mode: "timestamp" }).notNull(),
     updatedAt: integer("updated_at", { mode: "timestamp" }).notNull(),
   },